@@ -10,4 +10,16 @@ var (
 	ProtocGRpcMinVersion = 10200
 	SpinerIndex          = 9
 	Duration             = 77 * time.Millisecond
+	ShutdownTimeout      = 10 * time.Second
+	StartupTimeout       = 3 * time.Minute
+	WatchInterval        = 500 * time.Millisecond
+	WatchDebounce        = 300 * time.Millisecond
+	LogLevel             = "trace"
+	PrettyLog            = false
+	LogMaxSize           = int64(10 << 20)
+	LogBackups           = 5
+	Output               = "text"
+	Verbose              = false
+	Quiet                = false
+	Project              = ""
 )
@@ -2,7 +2,6 @@ package command
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -10,7 +9,6 @@ import (
 
 	"github.com/bimalabs/cli/bima"
 	"github.com/bimalabs/cli/tool"
-	"github.com/briandowns/spinner"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 	"mvdan.cc/sh/interp"
@@ -18,40 +16,247 @@ import (
 )
 
 func BuildAppCommand() *cli.Command {
+	var (
+		output   string
+		archive  bool
+		version  string
+		release  bool
+		cgo      bool
+		docker   bool
+		file     string
+		noConfig bool
+		noCache  bool
+	)
+
 	return &cli.Command{
-		Name:        "build",
-		Aliases:     []string{"install", "compile"},
-		Description: "build <name>",
+		Name:    "build",
+		Before:  inProject,
+		Aliases: []string{"install", "compile"},
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "os",
+				Usage: "Target operating system, repeat to build multiple targets",
+			},
+			&cli.StringSliceFlag{
+				Name:  "arch",
+				Usage: "Target architecture, repeat to build multiple targets",
+			},
+			&cli.StringFlag{
+				Name:        "output-dir",
+				Aliases:     []string{"o"},
+				Usage:       "Output directory for target binaries",
+				Destination: &output,
+			},
+			&cli.BoolFlag{
+				Name:        "archive",
+				Usage:       "Create tar.gz archive per target",
+				Destination: &archive,
+			},
+			&cli.StringFlag{
+				Name:        "version",
+				Usage:       "Version stamped into binary, default to git tag",
+				Destination: &version,
+				Action: func(_ *cli.Context, value string) error {
+					if strings.Contains(value, "'") && strings.Contains(value, `"`) {
+						return tool.Failf(tool.ExitUsage, "version %s must not contain both single and double quotes", value)
+					}
+
+					return nil
+				},
+			},
+			&cli.BoolFlag{
+				Name:        "release",
+				Usage:       "Build trimmed and reproducible release binary",
+				Destination: &release,
+			},
+			&cli.BoolFlag{
+				Name:        "cgo",
+				Usage:       "Enable cgo on release build",
+				Destination: &cgo,
+			},
+			&cli.BoolFlag{
+				Name:        "docker",
+				Usage:       "Write OCI image tarball per architecture instead of binaries",
+				Destination: &docker,
+			},
+			&cli.StringFlag{
+				Name:        "config",
+				Aliases:     []string{"c"},
+				Value:       ".env",
+				Usage:       "Config file copied into docker image",
+				Destination: &file,
+			},
+			&cli.BoolFlag{
+				Name:        "no-config",
+				Usage:       "Do not copy config into docker image, mount it at runtime instead",
+				Destination: &noConfig,
+			},
+			&cli.BoolFlag{
+				Name:        "no-cache",
+				Usage:       "Run clean and dump even when inputs are unchanged",
+				Destination: &noCache,
+			},
+		},
+		Description: "build [--os <os>...] [--arch <arch>...] [-o <dir>] [--archive] [--version <version>] [--release [--cgo]] [--docker [-c <config>] [--no-config]] [--no-cache] <name>",
 		Usage:       "Build application to binary",
 		Action: func(ctx *cli.Context) error {
 			name := ctx.Args().First()
 			if name == "" {
-				fmt.Println("Usage: bima build <name>")
+				return tool.Usage("bima build <name>")
+			}
 
-				return nil
+			for _, o := range ctx.StringSlice("os") {
+				if docker && o != "linux" {
+					return tool.Failf(tool.ExitUsage, "docker image only supports linux, got --os %s", o)
+				}
 			}
 
-			progress := spinner.New(spinner.CharSets[bima.SpinerIndex], bima.Duration)
-			progress.Suffix = " Bundling application... "
+			progress := tool.Spinner(" Bundling application... ")
 			progress.Start()
-			if err := tool.Call("clean"); err != nil {
+			pipeline := tool.NewPipeline(!noCache)
+			if err := pipeline.Run(tool.Clean(), tool.Dump()); err != nil {
 				progress.Stop()
-				color.New(color.FgRed).Println("Error cleaning dependencies")
 
 				return err
 			}
 
-			if err := tool.Call("dump"); err != nil {
+			stamp := tool.NewStamp(version, release)
+			oses, arches := ctx.StringSlice("os"), ctx.StringSlice("arch")
+			if len(oses) == 0 && len(arches) == 0 && output == "" && !archive && !release && !docker {
+				err := pipeline.Run(tool.Build(name, false, stamp.Flags()))
 				progress.Stop()
-				color.New(color.FgRed).Println("Error updating services container")
+				if err != nil {
+					return err
+				}
 
-				return err
+				stamp.Print()
+
+				return tool.Emit(map[string]interface{}{"binary": name, "stamp": stamp})
+			}
+
+			if output == "" {
+				output = "dist"
 			}
 
-			err := tool.Call("build", name, false)
+			if docker {
+				images := []string{}
+				for _, t := range tool.Targets([]string{"linux"}, arches) {
+					image, err := tool.Image(name, output, file, t.Arch, stamp, !noConfig)
+					if err != nil {
+						progress.Stop()
+
+						return tool.Fail(tool.ExitBuild, err)
+					}
+
+					images = append(images, image)
+				}
+
+				progress.Stop()
+				for _, v := range images {
+					fmt.Print("Image written to ")
+					color.New(color.Bold).Println(v)
+				}
+
+				stamp.Print()
+
+				return tool.Emit(map[string]interface{}{"images": images, "stamp": stamp})
+			}
+
+			bundle := tool.Bundle{
+				Name:    name,
+				Dir:     output,
+				Targets: tool.Targets(oses, arches),
+				Archive: archive,
+				Release: release,
+				Cgo:     cgo,
+				Stamp:   stamp,
+			}
+
+			artifacts, err := bundle.Build()
 			progress.Stop()
+			if err != nil {
+				return tool.Fail(tool.ExitBuild, err)
+			}
 
-			return err
+			util := color.New(color.Bold)
+			for _, v := range artifacts {
+				util.Printf("%-16s", v.Target)
+				fmt.Printf(" %s %s\n", v.Binary, color.New(color.Faint).Sprint(v.Checksum))
+				if v.Archive != "" {
+					fmt.Printf("%-16s %s\n", "", v.Archive)
+				}
+
+				if !release {
+					continue
+				}
+
+				fmt.Printf("%-16s %.2f MB ", "", float64(v.Size)/(1<<20))
+				status := color.New(color.FgGreen).Sprint("reproducible")
+				if !*v.Reproducible {
+					status = color.New(color.FgRed).Sprint("not reproducible")
+				}
+
+				fmt.Println(status)
+
+				for _, w := range v.Warnings {
+					color.New(color.FgYellow).Printf("%-16s Warning: %s\n", "", w)
+				}
+			}
+
+			fmt.Print("Checksums written to ")
+			util.Printf("%s/checksums.txt\n", output)
+			stamp.Print()
+
+			return tool.Emit(map[string]interface{}{"artifacts": artifacts, "checksums": fmt.Sprintf("%s/checksums.txt", output), "stamp": stamp})
+		},
+	}
+}
+
+func DockerizeCommand() *cli.Command {
+	var (
+		file     string
+		force    bool
+		noConfig bool
+	)
+
+	return &cli.Command{
+		Name:    "dockerize",
+		Before:  inProject,
+		Aliases: []string{"dkr"},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "config",
+				Aliases:     []string{"c"},
+				Value:       ".env",
+				Usage:       "Config file copied into image",
+				Destination: &file,
+			},
+			&cli.BoolFlag{
+				Name:        "no-config",
+				Usage:       "Exclude config from image, mount it at runtime instead",
+				Destination: &noConfig,
+			},
+			&cli.BoolFlag{
+				Name:        "force",
+				Usage:       "Overwrite existing Dockerfile and .dockerignore",
+				Destination: &force,
+			},
+		},
+		Description: "dockerize [-c <config>] [--no-config] [--force]",
+		Usage:       "Generate Dockerfile and .dockerignore",
+		Action: func(*cli.Context) error {
+			written, err := tool.Dockerize(file, force, !noConfig)
+			for _, v := range written {
+				fmt.Print("File ")
+				color.New(color.Bold).Print(v)
+				fmt.Println(" generated")
+			}
+
+			if err != nil {
+				return tool.Fail(tool.ExitGeneration, err)
+			}
+
+			return tool.Emit(map[string][]string{"files": written})
 		},
 	}
 }
@@ -59,16 +264,16 @@ func BuildAppCommand() *cli.Command {
 func DumpServiceContainerCommand() *cli.Command {
 	return &cli.Command{
 		Name:        "dump",
+		Before:      inProject,
 		Aliases:     []string{"dmp"},
 		Description: "dump",
 		Usage:       "Dump service container",
 		Action: func(*cli.Context) error {
-			progress := spinner.New(spinner.CharSets[bima.SpinerIndex], bima.Duration)
-			progress.Suffix = " Dumping service container... "
+			progress := tool.Spinner(" Dumping service container... ")
 			progress.Start()
 			time.Sleep(1 * time.Second)
 
-			err := tool.Call("dump")
+			err := tool.NewPipeline(false).Run(tool.Dump())
 			progress.Stop()
 
 			return err
@@ -76,33 +281,157 @@ func DumpServiceContainerCommand() *cli.Command {
 	}
 }
 
-func RunAppCommand(file string) *cli.Command {
+func RunAppCommand() *cli.Command {
+	var (
+		profile    string
+		detach     bool
+		foreground bool
+		httpPort   int
+		rpcPort    int
+		debugger   = tool.Debugger{}
+	)
+
 	return &cli.Command{
-		Name: "run",
-		Flags: []cli.Flag{
+		Name:   "run",
+		Before: inProject,
+		Flags: append(configFlags(),
+			&cli.DurationFlag{
+				Name:        "timeout",
+				Aliases:     []string{"t"},
+				Value:       bima.ShutdownTimeout,
+				Usage:       "Grace period before application is force killed",
+				Destination: &bima.ShutdownTimeout,
+			},
+			&cli.DurationFlag{
+				Name:        "wait",
+				Aliases:     []string{"w"},
+				Value:       bima.StartupTimeout,
+				Usage:       "Maximum time to wait for application to be ready",
+				Destination: &bima.StartupTimeout,
+			},
 			&cli.StringFlag{
-				Name:        "config",
-				Aliases:     []string{"c"},
-				Value:       ".env",
-				Usage:       "Config file",
-				Destination: &file,
+				Name:        "listen",
+				Value:       ":16517",
+				Usage:       "Debugger listen address",
+				Destination: &debugger.Listen,
 			},
-		},
+			&cli.BoolFlag{
+				Name:        "continue",
+				Usage:       "Continue application on debugger start",
+				Destination: &debugger.Continue,
+			},
+			&cli.BoolFlag{
+				Name:        "accept-multiclient",
+				Usage:       "Allow multiple debugger client connections",
+				Destination: &debugger.MultiClient,
+			},
+			&cli.BoolFlag{
+				Name:        "launch",
+				Usage:       "Launch application from debugger instead of attaching to it",
+				Destination: &debugger.Launch,
+			},
+			profileFlag(&profile),
+			&cli.BoolFlag{
+				Name:        "detach",
+				Aliases:     []string{"d"},
+				Usage:       "Run application in background",
+				Destination: &detach,
+			},
+			&cli.BoolFlag{
+				Name:        "foreground",
+				Usage:       "Run profile in foreground, used by detached application",
+				Hidden:      true,
+				Destination: &foreground,
+			},
+			&cli.IntFlag{
+				Name:        "http-port",
+				Usage:       "Override HTTP port of the profile",
+				Destination: &httpPort,
+			},
+			&cli.IntFlag{
+				Name:        "grpc-port",
+				Usage:       "Override gRPC port of the profile",
+				Destination: &rpcPort,
+			},
+			&cli.StringFlag{
+				Name:        "log-level",
+				Aliases:     []string{"l"},
+				Value:       bima.LogLevel,
+				Usage:       "Minimum log level to show (trace, debug, info, warn, error)",
+				Destination: &bima.LogLevel,
+			},
+			&cli.BoolFlag{
+				Name:        "pretty",
+				Usage:       "Pretty print json log",
+				Destination: &bima.PrettyLog,
+			},
+		),
 		Aliases:     []string{"rn"},
-		Description: "run <mode> [-c <config>]",
+		Description: "run <mode> [-c <config>...] [--set <KEY=VALUE>...] [-t <timeout>] [-w <wait>] [--listen <address>] [--continue] [--accept-multiclient] [--launch] [-d] [-p <profile> --http-port <port> --grpc-port <port>] [-l <level>] [--pretty]",
 		Usage:       "Run application using <config> file",
 		Action: func(ctx *cli.Context) error {
-			if tool.Pid() != 0 {
-				_ = tool.Call("kill")
+			config, err := tool.Materialize(ctx.StringSlice("config"), ctx.StringSlice("set"))
+			if err != nil {
+				return tool.Fail(tool.ExitEnvironment, err)
 			}
+			defer config.Close()
+
+			file := config.File
 
 			mode := ctx.Args().First()
+			if profile != "" || detach || foreground {
+				if mode != "" {
+					return tool.Failf(tool.ExitUsage, "profile and detach are not supported in %s mode", mode)
+				}
+
+				progress := tool.Spinner(" Preparing run mode... ")
+				progress.Start()
+				tasks := []tool.Task{tool.Dump()}
+				if foreground {
+					tasks = nil
+				}
+
+				if err := tool.NewPipeline(false).Run(tasks...); err != nil {
+					progress.Stop()
+
+					return err
+				}
+
+				app := tool.Profile(profile)
+				if detach {
+					pid, err := app.Detach(ctx.StringSlice("config"), ctx.StringSlice("set"), httpPort, rpcPort)
+					progress.Stop()
+					if err != nil {
+						return tool.Fail(tool.ExitEnvironment, err)
+					}
+
+					util := color.New(color.Bold)
+					color.New(color.FgGreen).Print("Application is running in background with PID ")
+					util.Println(pid)
+					fmt.Print("Logs are written to ")
+					util.Println(app.LogFile())
+
+					return nil
+				}
+
+				progress.Stop()
+
+				return tool.Fail(tool.ExitBuild, app.Run(file, httpPort, rpcPort))
+			}
+
+			if tool.Pid() != 0 {
+				_ = tool.Kill(bima.ShutdownTimeout)
+			}
+
+			if mode == "watch" {
+				return tool.Fail(tool.ExitEnvironment, tool.Watch(ctx.StringSlice("config"), ctx.StringSlice("set")))
+			}
+
 			if mode == "debug" {
-				progress := spinner.New(spinner.CharSets[bima.SpinerIndex], bima.Duration)
-				progress.Suffix = " Preparing debug mode... "
+				progress := tool.Spinner(" Preparing debug mode... ")
 				progress.Start()
 
-				err := tool.Call("build", "bima", true)
+				err := tool.NewPipeline(false).Run(tool.Compile("bima", true, ""))
 				if err != nil {
 					progress.Stop()
 
@@ -111,49 +440,200 @@ func RunAppCommand(file string) *cli.Command {
 
 				progress.Stop()
 
-				cmd, _ := syntax.NewParser().Parse(strings.NewReader(fmt.Sprintf("./bima run %s", file)), "")
-				runner, _ := interp.New(interp.Env(nil), interp.StdIO(nil, os.Stdout, os.Stdout))
+				if err := debugger.Configure(); err != nil {
+					return tool.NewFailure(tool.ExitGeneration, "Error generating debugger configuration", err)
+				}
 
 				ctx, cancel := context.WithCancel(context.Background())
 				defer cancel()
-				go func() {
-					_ = runner.Run(ctx, cmd)
-				}()
 
-				var pid = 0
-				for {
-					if pid != 0 {
-						break
-					}
+				if debugger.Launch {
+					return tool.Fail(tool.ExitToolchain, debugger.Exec(ctx, "./bima", file))
+				}
 
-					pid = tool.Pid()
-					if pid == 0 {
-						time.Sleep(100 * time.Millisecond)
+				cmd, _ := syntax.NewParser().Parse(strings.NewReader(fmt.Sprintf("./bima run %s", file)), "")
+				runner, _ := interp.New(interp.Env(nil), interp.StdIO(nil, os.Stdout, os.Stdout))
 
-						continue
-					}
-				}
+				go func() {
+					_ = runner.Run(ctx, cmd)
+				}()
 
-				if pid == 0 {
-					return errors.New("PID not exists")
+				pid, err := tool.WaitPid(bima.StartupTimeout)
+				if err != nil {
+					return tool.Fail(tool.ExitBuild, err)
 				}
 
-				return tool.Debug(ctx, pid)
+				return tool.Fail(tool.ExitToolchain, debugger.Attach(ctx, pid))
 			}
 
-			progress := spinner.New(spinner.CharSets[bima.SpinerIndex], bima.Duration)
-			progress.Suffix = " Preparing run mode... "
+			progress := tool.Spinner(" Preparing run mode... ")
 			progress.Start()
-			if err := tool.Call("dump"); err != nil {
+			if err := tool.NewPipeline(false).Run(tool.Dump()); err != nil {
 				progress.Stop()
-				color.New(color.FgRed).Println("Error updating services container")
 
 				return err
 			}
 
 			progress.Stop()
 
-			return tool.Call("run", file)
+			return tool.Fail(tool.ExitBuild, tool.Run(file))
+		},
+	}
+}
+
+func StopAppCommand() *cli.Command {
+	var profile string
+
+	return &cli.Command{
+		Name:   "stop",
+		Before: inProject,
+		Flags: []cli.Flag{
+			&cli.DurationFlag{
+				Name:        "timeout",
+				Aliases:     []string{"t"},
+				Value:       bima.ShutdownTimeout,
+				Usage:       "Grace period before application is force killed",
+				Destination: &bima.ShutdownTimeout,
+			},
+			profileFlag(&profile),
+		},
+		Aliases:     []string{"stp"},
+		Description: "stop [-t <timeout>] [-p <profile>]",
+		Usage:       "Stop running application",
+		Action: func(*cli.Context) error {
+			app := tool.Profile(profile)
+			if !app.Running() {
+				color.New(color.FgYellow).Println("Application is not running")
+
+				return tool.Fail(tool.ExitEnvironment, app.Stop(bima.ShutdownTimeout))
+			}
+
+			progress := tool.Spinner(" Stopping application... ")
+			progress.Start()
+
+			err := app.Stop(bima.ShutdownTimeout)
+			progress.Stop()
+			if err != nil {
+				return tool.NewFailure(tool.ExitEnvironment, "Error stopping application", err)
+			}
+
+			color.New(color.FgGreen).Println("Application stopped")
+
+			return nil
+		},
+	}
+}
+
+func StatusAppCommand() *cli.Command {
+	var profile string
+
+	return &cli.Command{
+		Name:        "status",
+		Before:      inProject,
+		Flags:       append(configFlags(), profileFlag(&profile)),
+		Aliases:     []string{"st"},
+		Description: "status [-c <config>...] [--set <KEY=VALUE>...] [-p <profile>]",
+		Usage:       "Show running application status",
+		Action: func(ctx *cli.Context) error {
+			env, err := tool.Load(ctx.StringSlice("config"), ctx.StringSlice("set"))
+			if err != nil {
+				return tool.Fail(tool.ExitEnvironment, err)
+			}
+
+			status, err := tool.Profile(profile).Inspect(env)
+			if err != nil {
+				return err
+			}
+
+			if tool.Json() {
+				return tool.Emit(status)
+			}
+
+			util := color.New(color.Bold)
+			if !status.Running {
+				util.Print("Status: ")
+				color.New(color.FgRed).Println("stopped")
+
+				return nil
+			}
+
+			util.Print("Status: ")
+			color.New(color.FgGreen).Println("running")
+			util.Print("PID: ")
+			fmt.Println(status.Pid)
+			util.Print("Uptime: ")
+			fmt.Println(status.Uptime)
+			for _, v := range status.Ports {
+				util.Printf("%s: ", strings.ToUpper(v.Name))
+				if v.Listening {
+					color.New(color.FgGreen).Printf("%d (responding)\n", v.Port)
+
+					continue
+				}
+
+				color.New(color.FgRed).Printf("%d (not responding)\n", v.Port)
+			}
+
+			return nil
+		},
+	}
+}
+
+func LogsAppCommand() *cli.Command {
+	var (
+		profile string
+		follow  bool
+		lines   int
+	)
+
+	return &cli.Command{
+		Name:   "logs",
+		Before: inProject,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:        "follow",
+				Aliases:     []string{"f"},
+				Usage:       "Follow log output",
+				Destination: &follow,
+			},
+			&cli.IntFlag{
+				Name:        "lines",
+				Aliases:     []string{"n"},
+				Value:       100,
+				Usage:       "Number of last lines to show",
+				Destination: &lines,
+			},
+			profileFlag(&profile),
+			&cli.StringFlag{
+				Name:        "log-level",
+				Aliases:     []string{"l"},
+				Value:       bima.LogLevel,
+				Usage:       "Minimum log level to show (trace, debug, info, warn, error)",
+				Destination: &bima.LogLevel,
+			},
+			&cli.BoolFlag{
+				Name:        "pretty",
+				Usage:       "Pretty print json log",
+				Destination: &bima.PrettyLog,
+			},
+		},
+		Aliases:     []string{"log"},
+		Description: "logs [-f] [-n <lines>] [-p <profile>] [-l <level>] [--pretty]",
+		Usage:       "Show captured application logs",
+		Action: func(*cli.Context) error {
+			return tool.Fail(tool.ExitEnvironment, tool.Logs(tool.Profile(profile), follow, lines))
+		},
+	}
+}
+
+func profileFlag(destination *string) cli.Flag {
+	return &cli.StringFlag{
+		Name:        "profile",
+		Aliases:     []string{"p"},
+		Usage:       "Application profile name, letters, digits, _ and - only",
+		Destination: destination,
+		Action: func(_ *cli.Context, name string) error {
+			return tool.Profile(name).Validate()
 		},
 	}
 }
@@ -0,0 +1,251 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/bimalabs/cli/tool"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
+)
+
+func ConfigCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "config",
+		Aliases:     []string{"cfg"},
+		Usage:       "Inspect, validate, convert and encrypt application config",
+		Description: "config <command>",
+		Subcommands: []*cli.Command{configShow(), configValidate(), configConvert(), configEncrypt(), configDecrypt()},
+	}
+}
+
+func configShow() *cli.Command {
+	return &cli.Command{
+		Name:        "show",
+		Before:      inProject,
+		Flags:       configFlags(),
+		Description: "config show [-c <config>...] [--set <KEY=VALUE>...]",
+		Usage:       "Show merged config from <config> files and overrides",
+		Action: func(ctx *cli.Context) error {
+			env, err := tool.Load(ctx.StringSlice("config"), ctx.StringSlice("set"))
+			if err != nil {
+				return tool.Fail(tool.ExitEnvironment, err)
+			}
+
+			if tool.Json() {
+				return tool.Emit(env)
+			}
+
+			content, err := yaml.Marshal(env)
+			if err != nil {
+				return err
+			}
+
+			fmt.Print(string(content))
+
+			return nil
+		},
+	}
+}
+
+func configValidate() *cli.Command {
+	return &cli.Command{
+		Name:   "validate",
+		Before: inProject,
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "config",
+				Aliases: []string{"c"},
+				Value:   cli.NewStringSlice(".env"),
+				Usage:   "Config file, repeat to layer files in order",
+			},
+		},
+		Aliases:     []string{"check"},
+		Description: "config validate [-c <config>...]",
+		Usage:       "Validate <config> files against framework config",
+		Action: func(ctx *cli.Context) error {
+			problems := tool.Validate(ctx.StringSlice("config"))
+			if err := tool.Emit(map[string]interface{}{"valid": len(problems) == 0, "problems": problems}); err != nil {
+				return err
+			}
+
+			if len(problems) == 0 {
+				color.New(color.FgGreen).Println("Config is valid")
+
+				return nil
+			}
+
+			util := color.New(color.FgRed)
+			for _, v := range problems {
+				if v.Key == "" {
+					util.Printf("%s: %s\n", v.File, v.Message)
+
+					continue
+				}
+
+				util.Printf("%s: %s %s\n", v.File, color.New(color.FgRed, color.Bold).Sprint(v.Key), v.Message)
+			}
+
+			return tool.Failf(tool.ExitEnvironment, "config has %d problem(s)", len(problems))
+		},
+	}
+}
+
+func configConvert() *cli.Command {
+	var (
+		from       string
+		to         string
+		force      bool
+		references bool
+	)
+
+	return &cli.Command{
+		Name:   "convert",
+		Before: inProject,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "from",
+				Aliases:     []string{"f"},
+				Value:       ".env",
+				Usage:       "Source config file",
+				Destination: &from,
+			},
+			&cli.StringFlag{
+				Name:        "to",
+				Aliases:     []string{"t"},
+				Usage:       "Target config file",
+				Required:    true,
+				Destination: &to,
+			},
+			&cli.BoolFlag{
+				Name:        "force",
+				Usage:       "Overwrite target config file when exists",
+				Destination: &force,
+			},
+			&cli.BoolFlag{
+				Name:        "update-references",
+				Usage:       "Replace source config file references in Dockerfile, Taskfile and compose files",
+				Destination: &references,
+			},
+		},
+		Aliases:     []string{"conv"},
+		Description: "config convert [-f <from>] -t <to> [--force] [--update-references]",
+		Usage:       "Convert config between .env, yaml and json format",
+		Action: func(*cli.Context) error {
+			if err := tool.Convert(from, to, force); err != nil {
+				return tool.Fail(tool.ExitGeneration, err)
+			}
+
+			util := color.New(color.Bold)
+			fmt.Print("Config ")
+			util.Print(from)
+			fmt.Print(" converted to ")
+			util.Println(to)
+			if !references {
+				return tool.Emit(map[string]string{"from": from, "to": to})
+			}
+
+			updated, err := tool.References(from, to)
+			for _, v := range updated {
+				fmt.Print("Reference updated in ")
+				util.Println(v)
+			}
+
+			if err != nil {
+				return tool.Fail(tool.ExitGeneration, err)
+			}
+
+			return tool.Emit(map[string]interface{}{"from": from, "to": to, "references": updated})
+		},
+	}
+}
+
+func configEncrypt() *cli.Command {
+	var file string
+
+	return &cli.Command{
+		Name:   "encrypt",
+		Before: inProject,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "config",
+				Aliases:     []string{"c"},
+				Value:       ".env",
+				Usage:       "Config file",
+				Destination: &file,
+			},
+		},
+		Aliases:     []string{"enc"},
+		Description: "config encrypt <key> [-c <config>]",
+		Usage:       "Encrypt value of <key> in <config> file",
+		Action: func(ctx *cli.Context) error {
+			key := ctx.Args().First()
+			if key == "" {
+				return tool.Usage("bima config encrypt <key>")
+			}
+
+			if err := tool.Encrypt(file, key); err != nil {
+				return tool.Fail(tool.ExitEnvironment, err)
+			}
+
+			fmt.Print("Value of ")
+			color.New(color.Bold).Print(key)
+			fmt.Println(" encrypted")
+
+			return tool.Emit(map[string]string{"file": file, "key": key, "action": "encrypt"})
+		},
+	}
+}
+
+func configDecrypt() *cli.Command {
+	var file string
+
+	return &cli.Command{
+		Name:   "decrypt",
+		Before: inProject,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "config",
+				Aliases:     []string{"c"},
+				Value:       ".env",
+				Usage:       "Config file",
+				Destination: &file,
+			},
+		},
+		Aliases:     []string{"dec"},
+		Description: "config decrypt <key> [-c <config>]",
+		Usage:       "Decrypt value of <key> in <config> file",
+		Action: func(ctx *cli.Context) error {
+			key := ctx.Args().First()
+			if key == "" {
+				return tool.Usage("bima config decrypt <key>")
+			}
+
+			if err := tool.Decrypt(file, key); err != nil {
+				return tool.Fail(tool.ExitEnvironment, err)
+			}
+
+			fmt.Print("Value of ")
+			color.New(color.Bold).Print(key)
+			fmt.Println(" decrypted")
+
+			return tool.Emit(map[string]string{"file": file, "key": key, "action": "decrypt"})
+		},
+	}
+}
+
+func configFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:    "config",
+			Aliases: []string{"c"},
+			Value:   cli.NewStringSlice(".env"),
+			Usage:   "Config file, repeat to layer files in order",
+		},
+		&cli.StringSliceFlag{
+			Name:    "set",
+			Aliases: []string{"s"},
+			Usage:   "Override config value using KEY=VALUE",
+		},
+	}
+}
@@ -1,8 +1,6 @@
 package command
 
 import (
-	"fmt"
-
 	"github.com/bimalabs/cli/tool"
 	"github.com/urfave/cli/v2"
 )
@@ -26,12 +24,10 @@ func createPackage() *cli.Command {
 		Action: func(ctx *cli.Context) error {
 			name := ctx.Args().First()
 			if name == "" {
-				fmt.Println("Usage: bima create app <name>")
-
-				return nil
+				return tool.Usage("bima create app <name>")
 			}
 
-			return tool.App(name).Create()
+			return tool.Fail(tool.ExitGeneration, tool.App(name).Create())
 		},
 	}
 }
@@ -39,18 +35,17 @@ func createPackage() *cli.Command {
 func createMiddleware() *cli.Command {
 	return &cli.Command{
 		Name:        "middleware",
+		Before:      inProject,
 		Aliases:     []string{"mid"},
 		Description: "bima create middleware <name>",
 		Usage:       "Create new middleware",
 		Action: func(ctx *cli.Context) error {
 			name := ctx.Args().First()
 			if name == "" {
-				fmt.Println("Usage: bima create middleware <name>")
-
-				return nil
+				return tool.Usage("bima create middleware <name>")
 			}
 
-			return tool.Middleware(name).Create()
+			return tool.Fail(tool.ExitGeneration, tool.Middleware(name).Create())
 		},
 	}
 }
@@ -58,18 +53,17 @@ func createMiddleware() *cli.Command {
 func createDriver() *cli.Command {
 	return &cli.Command{
 		Name:        "driver",
+		Before:      inProject,
 		Aliases:     []string{"dvr"},
 		Description: "bima create driver <name>",
 		Usage:       "Create new driver",
 		Action: func(ctx *cli.Context) error {
 			name := ctx.Args().First()
 			if name == "" {
-				fmt.Println("Usage: bima create driver <name>")
-
-				return nil
+				return tool.Usage("bima create driver <name>")
 			}
 
-			return tool.Driver(name).Create()
+			return tool.Fail(tool.ExitGeneration, tool.Driver(name).Create())
 		},
 	}
 }
@@ -77,18 +71,17 @@ func createDriver() *cli.Command {
 func createAdapter() *cli.Command {
 	return &cli.Command{
 		Name:        "adapter",
+		Before:      inProject,
 		Aliases:     []string{"adp"},
 		Description: "bima create adapter <name>",
 		Usage:       "Create new adapter",
 		Action: func(ctx *cli.Context) error {
 			name := ctx.Args().First()
 			if name == "" {
-				fmt.Println("Usage: bima create adapter <name>")
-
-				return nil
+				return tool.Usage("bima create adapter <name>")
 			}
 
-			return tool.Adapter(name).Create()
+			return tool.Fail(tool.ExitGeneration, tool.Adapter(name).Create())
 		},
 	}
 }
@@ -96,18 +89,17 @@ func createAdapter() *cli.Command {
 func createRoute() *cli.Command {
 	return &cli.Command{
 		Name:        "route",
+		Before:      inProject,
 		Aliases:     []string{"rt"},
 		Description: "bima create route <name>",
 		Usage:       "Create new route",
 		Action: func(ctx *cli.Context) error {
 			name := ctx.Args().First()
 			if name == "" {
-				fmt.Println("Usage: bima create route <name>")
-
-				return nil
+				return tool.Usage("bima create route <name>")
 			}
 
-			return tool.Route(name).Create()
+			return tool.Fail(tool.ExitGeneration, tool.Route(name).Create())
 		},
 	}
 }
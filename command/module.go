@@ -7,40 +7,43 @@ import (
 	"github.com/urfave/cli/v2"
 )
 
-func ModuleCommand(file string) *cli.Command {
+func ModuleCommand() *cli.Command {
 	return &cli.Command{
 		Name:        "module",
 		Aliases:     []string{"mod"},
-		Usage:       "Create or remove module",
+		Usage:       "Create, list or remove module",
 		Description: "module <command>",
-		Subcommands: []*cli.Command{moduleAdd(file), removeModule()},
+		Subcommands: []*cli.Command{moduleAdd(), listModule(), removeModule()},
 	}
 }
 
-func moduleAdd(file string) *cli.Command {
+func moduleAdd() *cli.Command {
 	return &cli.Command{
-		Name: "add",
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:        "config",
-				Aliases:     []string{"c"},
-				Value:       ".env",
-				Usage:       "Config file",
-				Destination: &file,
-			},
-		},
+		Name:        "add",
+		Before:      inProject,
+		Flags:       configFlags(),
 		Aliases:     []string{"new"},
-		Description: "module add <name> [-c <config>]",
+		Description: "module add <name> [-c <config>...] [--set <KEY=VALUE>...]",
 		Usage:       "Create new module <name> use <config> file",
 		Action: func(ctx *cli.Context) error {
 			name := ctx.Args().First()
 			if name == "" {
-				fmt.Println("Usage: bima module add <name> [-c <config>]")
+				return tool.Usage("bima module add <name> [-c <config>...] [--set <KEY=VALUE>...]")
+			}
 
-				return nil
+			config, err := tool.Materialize(ctx.StringSlice("config"), ctx.StringSlice("set"))
+			if err != nil {
+				return tool.Fail(tool.ExitEnvironment, err)
 			}
+			defer config.Close()
 
-			return tool.Module(name).Create(file)
+			file := config.File
+
+			if err := tool.Module(name).Create(file); err != nil {
+				return tool.Fail(tool.ExitGeneration, err)
+			}
+
+			return tool.Emit(map[string]string{"module": name, "action": "add"})
 		},
 	}
 }
@@ -48,18 +51,39 @@ func moduleAdd(file string) *cli.Command {
 func removeModule() *cli.Command {
 	return &cli.Command{
 		Name:        "remove",
+		Before:      inProject,
 		Aliases:     []string{"rm", "rem"},
 		Description: "module remove <name>",
 		Usage:       "Remove module <name>",
 		Action: func(ctx *cli.Context) error {
 			name := ctx.Args().First()
 			if name == "" {
-				fmt.Println("Usage: bima module remove <name>")
+				return tool.Usage("bima module remove <name>")
+			}
 
-				return nil
+			if err := tool.Module(name).Remove(); err != nil {
+				return err
+			}
+
+			return tool.Emit(map[string]string{"module": name, "action": "remove"})
+		},
+	}
+}
+
+func listModule() *cli.Command {
+	return &cli.Command{
+		Name:        "list",
+		Before:      inProject,
+		Aliases:     []string{"ls"},
+		Description: "module list",
+		Usage:       "List registered modules",
+		Action: func(*cli.Context) error {
+			modules := tool.Modules()
+			for _, v := range modules {
+				fmt.Println(v)
 			}
 
-			return tool.Module(name).Remove()
+			return tool.Emit(map[string][]string{"modules": modules})
 		},
 	}
 }
@@ -0,0 +1,62 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+
+	"github.com/bimalabs/cli/tool"
+	"github.com/urfave/cli/v2"
+)
+
+func Plugin(ctx *cli.Context) error {
+	if !ctx.Args().Present() {
+		return cli.ShowAppHelp(ctx)
+	}
+
+	name := ctx.Args().First()
+	plugin, ok := tool.Lookup(name)
+	if !ok {
+		return tool.Failf(tool.ExitUsage, "Command %s not found, no bima-%s plugin on PATH or in .bima/plugins", name, name)
+	}
+
+	if err := nearProject(ctx); err != nil {
+		return err
+	}
+
+	err := plugin.Exec(ctx.Args().Tail())
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return cli.Exit("", exit.ExitCode())
+	}
+
+	return err
+}
+
+func PluginHelp(printer func(io.Writer, string, interface{})) func(io.Writer, string, interface{}) {
+	return func(w io.Writer, template string, data interface{}) {
+		printer(w, template, data)
+
+		app, ok := data.(*cli.App)
+		if !ok {
+			return
+		}
+
+		plugins := []tool.Plugin{}
+		for _, p := range tool.Plugins() {
+			if app.Command(p.Name) == nil {
+				plugins = append(plugins, p)
+			}
+		}
+
+		if len(plugins) == 0 {
+			return
+		}
+
+		fmt.Fprintln(w, "\nPLUGINS:")
+		for _, p := range plugins {
+			fmt.Fprintf(w, "   %s\tRun plugin %s\n", p.Name, p.Path)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package command
+
+import (
+	"path/filepath"
+
+	"github.com/bimalabs/cli/bima"
+	"github.com/bimalabs/cli/tool"
+	"github.com/urfave/cli/v2"
+)
+
+var paths = map[string]bool{"config": true, "from": true, "to": true, "output-dir": true}
+
+func inProject(ctx *cli.Context) error {
+	if err := absolute(ctx); err != nil {
+		return err
+	}
+
+	return tool.Enter(bima.Project)
+}
+
+func nearProject(ctx *cli.Context) error {
+	if bima.Project != "" {
+		return inProject(ctx)
+	}
+
+	if err := absolute(ctx); err != nil {
+		return err
+	}
+
+	_ = tool.Enter("")
+
+	return nil
+}
+
+func absolute(ctx *cli.Context) error {
+	for _, f := range ctx.Command.Flags {
+		name := f.Names()[0]
+		if !paths[name] || !ctx.IsSet(name) {
+			continue
+		}
+
+		if _, ok := f.(*cli.StringSliceFlag); ok {
+			values := ctx.StringSlice(name)
+			for i, v := range values {
+				path, err := filepath.Abs(v)
+				if err != nil {
+					return tool.Fail(tool.ExitUsage, err)
+				}
+
+				values[i] = path
+			}
+
+			continue
+		}
+
+		path, err := filepath.Abs(ctx.String(name))
+		if err != nil {
+			return tool.Fail(tool.ExitUsage, err)
+		}
+
+		if err := ctx.Set(name, path); err != nil {
+			return tool.Fail(tool.ExitUsage, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/bimalabs/cli/tool"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+func TaskCommand() *cli.Command {
+	var list bool
+
+	return &cli.Command{
+		Name:    "task",
+		Before:  inProject,
+		Aliases: []string{"t"},
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:        "list",
+				Aliases:     []string{"l"},
+				Usage:       "List tasks defined in bima.yaml",
+				Destination: &list,
+			},
+		},
+		Description: "task [--list] <name> [-- <args>...]",
+		Usage:       "Run project task defined in bima.yaml",
+		BashComplete: func(ctx *cli.Context) {
+			if ctx.NArg() > 0 {
+				return
+			}
+
+			_ = nearProject(ctx)
+			project, err := tool.LoadProject()
+			if err != nil {
+				return
+			}
+
+			for _, name := range project.Names() {
+				fmt.Println(name)
+			}
+		},
+		Action: func(ctx *cli.Context) error {
+			project, err := tool.LoadProject()
+			if err != nil {
+				return tool.Fail(tool.ExitEnvironment, err)
+			}
+
+			if list {
+				if tool.Json() {
+					return tool.Emit(project.Tasks)
+				}
+
+				if len(project.Tasks) == 0 {
+					fmt.Println("No task defined in bima.yaml")
+
+					return nil
+				}
+
+				util := color.New(color.Bold)
+				for _, name := range project.Names() {
+					util.Printf("%-20s", name)
+					fmt.Printf(" %s\n", project.Tasks[name].Description)
+				}
+
+				return nil
+			}
+
+			name := ctx.Args().First()
+			if name == "" {
+				return tool.Usage("bima task <name>")
+			}
+
+			args := ctx.Args().Tail()
+			if len(args) > 0 && args[0] == "--" {
+				args = args[1:]
+			}
+
+			task, err := project.Task(name, args)
+			if err != nil {
+				return tool.Fail(tool.ExitUsage, err)
+			}
+
+			if err := tool.NewPipeline(false).Run(task); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+}
@@ -2,37 +2,24 @@ package command
 
 import (
 	"fmt"
-	"os"
-	"strings"
 
 	"github.com/bimalabs/cli/bima"
 	"github.com/bimalabs/cli/tool"
-	"github.com/briandowns/spinner"
-	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
-	"golang.org/x/mod/modfile"
 )
 
 func UpdateDependenciesCommand() *cli.Command {
 	return &cli.Command{
 		Name:        "update",
+		Before:      inProject,
 		Aliases:     []string{"upd"},
 		Description: "update",
 		Usage:       "Update project dependencies",
 		Action: func(*cli.Context) error {
-			progress := spinner.New(spinner.CharSets[bima.SpinerIndex], bima.Duration)
-			progress.Suffix = " Updating dependencies... "
+			progress := tool.Spinner(" Updating dependencies... ")
 			progress.Start()
-			if err := tool.Call("update"); err != nil {
+			if err := tool.NewPipeline(false).Run(tool.Update(), tool.Dump()); err != nil {
 				progress.Stop()
-				color.New(color.FgRed).Println("Error updating dependencies")
-
-				return err
-			}
-
-			if err := tool.Call("dump"); err != nil {
-				progress.Stop()
-				color.New(color.FgRed).Println("Error updating services container")
 
 				return err
 			}
@@ -47,23 +34,15 @@ func UpdateDependenciesCommand() *cli.Command {
 func CleanDependenciesCommand() *cli.Command {
 	return &cli.Command{
 		Name:        "clean",
+		Before:      inProject,
 		Aliases:     []string{"cln"},
 		Description: "clean",
 		Usage:       "Cleaning project dependencies",
 		Action: func(*cli.Context) error {
-			progress := spinner.New(spinner.CharSets[bima.SpinerIndex], bima.Duration)
-			progress.Suffix = " Cleaning dependencies... "
+			progress := tool.Spinner(" Cleaning dependencies... ")
 			progress.Start()
-			if err := tool.Call("clean"); err != nil {
+			if err := tool.NewPipeline(false).Run(tool.Clean(), tool.Dump()); err != nil {
 				progress.Stop()
-				color.New(color.FgRed).Println("Error cleaning dependencies")
-
-				return err
-			}
-
-			if err := tool.Call("dump"); err != nil {
-				progress.Stop()
-				color.New(color.FgRed).Println("Error updating services container")
 
 				return err
 			}
@@ -76,32 +55,26 @@ func CleanDependenciesCommand() *cli.Command {
 }
 
 func GenerateProtobufCommand() *cli.Command {
+	var noCache bool
+
 	return &cli.Command{
-		Name:        "generate",
-		Aliases:     []string{"gen", "genproto"},
-		Description: "generate",
+		Name:    "generate",
+		Before:  inProject,
+		Aliases: []string{"gen", "genproto"},
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:        "no-cache",
+				Usage:       "Run every step even when inputs are unchanged",
+				Destination: &noCache,
+			},
+		},
+		Description: "generate [--no-cache]",
 		Usage:       "Generate code from protobuf file(s)",
 		Action: func(*cli.Context) error {
-			progress := spinner.New(spinner.CharSets[bima.SpinerIndex], bima.Duration)
-			progress.Suffix = " Generating codes from protobuff file(s)... "
+			progress := tool.Spinner(" Generating codes from protobuff file(s)... ")
 			progress.Start()
-			if err := tool.Call("genproto"); err != nil {
-				progress.Stop()
-				color.New(color.FgRed).Println("Error generate protobuff")
-
-				return err
-			}
-
-			if err := tool.Call("clean"); err != nil {
+			if err := tool.NewPipeline(!noCache).Run(tool.Genproto(), tool.Clean(), tool.Dump()); err != nil {
 				progress.Stop()
-				color.New(color.FgRed).Println("Error cleaning dependencies")
-
-				return err
-			}
-
-			if err := tool.Call("dump"); err != nil {
-				progress.Stop()
-				color.New(color.FgRed).Println("Error updating services container")
 
 				return err
 			}
@@ -116,46 +89,18 @@ func GenerateProtobufCommand() *cli.Command {
 func CheckVersionCommand() *cli.Command {
 	return &cli.Command{
 		Name:        "version",
+		Before:      nearProject,
 		Aliases:     []string{"v"},
 		Description: "version",
 		Usage:       "Show cli and framework version",
 		Action: func(*cli.Context) error {
-			wd, _ := os.Getwd()
-			var path strings.Builder
-
-			path.WriteString(wd)
-			path.WriteString("/go.mod")
-
-			framework := "unknown"
-			mod, err := os.ReadFile(path.String())
-			if err != nil {
-				fmt.Printf("Framework: %s\n", framework)
-				fmt.Printf("Cli: %s\n", bima.Version)
-
-				return nil
-			}
-
-			f, err := modfile.Parse(path.String(), mod, nil)
-			if err != nil {
-				fmt.Printf("Framework: %s\n", framework)
-				fmt.Printf("Cli: %s\n", bima.Version)
-
-				return nil
-			}
-
-			for _, v := range f.Require {
-				if v.Mod.Path == "github.com/bimalabs/framework/v4" {
-					framework = v.Mod.Version
-
-					break
-				}
-			}
+			framework := tool.Framework()
 
 			fmt.Printf("Framework: %s\n", framework)
 			fmt.Printf("Cli: %s\n", bima.Version)
 			fmt.Printf("SKeleton: %s\n", bima.SkeletonVersion)
 
-			return nil
+			return tool.Emit(map[string]string{"framework": framework, "cli": bima.Version, "skeleton": bima.SkeletonVersion})
 		},
 	}
 }
@@ -163,11 +108,12 @@ func CheckVersionCommand() *cli.Command {
 func MakesureToolchainInstalledCommand() *cli.Command {
 	return &cli.Command{
 		Name:        "makesure",
+		Before:      nearProject,
 		Aliases:     []string{"mks"},
 		Description: "makesure",
 		Usage:       "Check and install toolchain when it possible",
 		Action: func(ctx *cli.Context) error {
-			return tool.Call("makesure", bima.ProtocMinVersion, bima.ProtocGoMinVersion, bima.ProtocGRpcMinVersion)
+			return tool.Fail(tool.ExitToolchain, tool.Makesure(bima.ProtocMinVersion, bima.ProtocGoMinVersion, bima.ProtocGRpcMinVersion))
 		},
 	}
 }
@@ -179,7 +125,7 @@ func UpgradeCliCommand() *cli.Command {
 		Description: "upgrade",
 		Usage:       "Upgrade cli to latest version",
 		Action: func(*cli.Context) error {
-			return tool.Call("upgrade", bima.Version)
+			return tool.Upgrade(bima.Version)
 		},
 	}
 }
@@ -1,25 +1,78 @@
 package main
 
 import (
-	"log"
 	"os"
 
+	"github.com/bimalabs/cli/bima"
 	"github.com/bimalabs/cli/command"
+	"github.com/bimalabs/cli/tool"
 	"github.com/urfave/cli/v2"
 )
 
 func main() {
-	file := ""
 	app := &cli.App{
-		Name:                 "bima",
-		Usage:                "Bima Framework Toolkit",
-		Description:          "bima version",
-		EnableBashCompletion: true,
+		Name:                      "bima",
+		Usage:                     "Bima Framework Toolkit",
+		Description:               "bima version",
+		EnableBashCompletion:      true,
+		DisableSliceFlagSeparator: true,
+		Action:                    command.Plugin,
+		ExitErrHandler: func(ctx *cli.Context, err error) {
+			if err != nil && !tool.Json() {
+				tool.Report(err)
+				cli.OsExiter(tool.Code(err))
+			}
+		},
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:        "verbose",
+				Usage:       "Trace executed tasks and external commands with timing",
+				Destination: &bima.Verbose,
+			},
+			&cli.BoolFlag{
+				Name:        "quiet",
+				Aliases:     []string{"q"},
+				Usage:       "Hide progress and external command output unless it fails",
+				Destination: &bima.Quiet,
+			},
+			&cli.StringFlag{
+				Name:        "project",
+				Usage:       "Project directory, default to nearest bima project from current directory",
+				Destination: &bima.Project,
+			},
+			&cli.StringFlag{
+				Name:        "output",
+				Value:       "text",
+				Usage:       "Output format, text or json",
+				Destination: &bima.Output,
+			},
+		},
+		Before: func(*cli.Context) error {
+			if bima.Verbose && bima.Quiet {
+				return tool.Failf(tool.ExitUsage, "--verbose and --quiet can not be used together")
+			}
+
+			switch bima.Output {
+			case "json":
+				tool.Redirect()
+			case "text":
+			default:
+				return tool.Failf(tool.ExitUsage, "unknown output format %s", bima.Output)
+			}
+
+			return nil
+		},
 		Commands: []*cli.Command{
 			command.CreateCommand(),
-			command.ModuleCommand(file),
+			command.ModuleCommand(),
 			command.BuildAppCommand(),
-			command.RunAppCommand(file),
+			command.DockerizeCommand(),
+			command.RunAppCommand(),
+			command.StopAppCommand(),
+			command.StatusAppCommand(),
+			command.LogsAppCommand(),
+			command.ConfigCommand(),
+			command.TaskCommand(),
 			command.DumpServiceContainerCommand(),
 			command.UpdateDependenciesCommand(),
 			command.CleanDependenciesCommand(),
@@ -30,7 +83,26 @@ func main() {
 		},
 	}
 
-	if err := app.Run(os.Args); err != nil {
-		log.Fatal(err)
+	cli.HelpPrinter = command.PluginHelp(cli.HelpPrinter)
+	err := app.Run(os.Args)
+	if tool.Json() {
+		if err != nil {
+			if !tool.Emitted() {
+				_ = tool.Emit(map[string]interface{}{"ok": false, "error": err.Error()})
+			}
+
+			os.Exit(tool.Code(err))
+		}
+
+		if !tool.Emitted() {
+			_ = tool.Emit(map[string]interface{}{"ok": true})
+		}
+
+		return
+	}
+
+	if err != nil {
+		tool.Report(err)
+		os.Exit(tool.Code(err))
 	}
 }
@@ -0,0 +1,265 @@
+package tool
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+type (
+	Target struct {
+		Os   string `json:"os"`
+		Arch string `json:"arch"`
+	}
+
+	Artifact struct {
+		Target       Target   `json:"target"`
+		Binary       string   `json:"binary"`
+		Archive      string   `json:"archive,omitempty"`
+		Checksum     string   `json:"checksum"`
+		Size         int64    `json:"size"`
+		Reproducible *bool    `json:"reproducible,omitempty"`
+		Warnings     []string `json:"warnings,omitempty"`
+	}
+
+	Bundle struct {
+		Name    string
+		Dir     string
+		Targets []Target
+		Archive bool
+		Release bool
+		Cgo     bool
+		Stamp   Stamp
+	}
+)
+
+func Targets(oses []string, arches []string) []Target {
+	if len(oses) == 0 {
+		oses = []string{runtime.GOOS}
+	}
+
+	if len(arches) == 0 {
+		arches = []string{runtime.GOARCH}
+	}
+
+	targets := []Target{}
+	for _, o := range oses {
+		for _, a := range arches {
+			targets = append(targets, Target{Os: o, Arch: a})
+		}
+	}
+
+	return targets
+}
+
+func (t Target) String() string {
+	return fmt.Sprintf("%s/%s", t.Os, t.Arch)
+}
+
+func (t Target) binary(name string) string {
+	binary := fmt.Sprintf("%s_%s_%s", filepath.Base(name), t.Os, t.Arch)
+	if t.Os == "windows" {
+		binary = fmt.Sprintf("%s.exe", binary)
+	}
+
+	return binary
+}
+
+func (b Bundle) Build() ([]Artifact, error) {
+	if err := os.MkdirAll(b.Dir, 0755); err != nil {
+		return nil, err
+	}
+
+	artifacts := []Artifact{}
+	sums := []string{}
+	for _, t := range b.Targets {
+		artifact := Artifact{Target: t, Binary: fmt.Sprintf("%s/%s", b.Dir, t.binary(b.Name))}
+		err := hook("build", map[string]string{"BIMA_BUILD_TARGET": t.String(), "BIMA_BUILD_OUTPUT": artifact.Binary}, func() error {
+			return b.compile(t, artifact.Binary)
+		})
+		if err != nil {
+			return artifacts, fmt.Errorf("build %s failed: %s", t, err.Error())
+		}
+
+		sum, err := checksum(artifact.Binary)
+		if err != nil {
+			return artifacts, err
+		}
+
+		info, err := os.Stat(artifact.Binary)
+		if err != nil {
+			return artifacts, err
+		}
+
+		artifact.Checksum = sum
+		artifact.Size = info.Size()
+		if b.Release {
+			reproducible, warnings, err := b.verify(t, artifact)
+			if err != nil {
+				return artifacts, err
+			}
+
+			artifact.Reproducible = &reproducible
+			artifact.Warnings = warnings
+		}
+
+		sums = append(sums, fmt.Sprintf("%s  %s", sum, filepath.Base(artifact.Binary)))
+		if b.Archive {
+			artifact.Archive = fmt.Sprintf("%s/%s.tar.gz", b.Dir, strings.TrimSuffix(t.binary(b.Name), ".exe"))
+			if err := compress(artifact.Binary, artifact.Archive); err != nil {
+				return artifacts, err
+			}
+
+			sum, err := checksum(artifact.Archive)
+			if err != nil {
+				return artifacts, err
+			}
+
+			sums = append(sums, fmt.Sprintf("%s  %s", sum, filepath.Base(artifact.Archive)))
+		}
+
+		artifacts = append(artifacts, artifact)
+	}
+
+	return artifacts, os.WriteFile(fmt.Sprintf("%s/checksums.txt", b.Dir), []byte(strings.Join(sums, "\n")+"\n"), 0644)
+}
+
+func (b Bundle) compile(t Target, output string) error {
+	if !b.Release {
+		return command("GOOS=%s GOARCH=%s go build -ldflags %s -o %s cmd/main.go").run(t.Os, t.Arch, escape(b.Stamp.Flags()), escape(output))
+	}
+
+	cgo := 0
+	if b.Cgo {
+		cgo = 1
+	}
+
+	return command("GOOS=%s GOARCH=%s CGO_ENABLED=%d GOFLAGS=-mod=readonly go build -trimpath -buildvcs=false -ldflags %s -o %s cmd/main.go").run(t.Os, t.Arch, cgo, escape("-s -w "+b.Stamp.Flags()), escape(output))
+}
+
+func (b Bundle) verify(t Target, artifact Artifact) (bool, []string, error) {
+	dir, err := os.MkdirTemp("", "bima-release")
+	if err != nil {
+		return false, nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	rebuild := fmt.Sprintf("%s/%s", dir, filepath.Base(artifact.Binary))
+	if err := b.compile(t, rebuild); err != nil {
+		return false, nil, fmt.Errorf("rebuild %s failed: %s", t, err.Error())
+	}
+
+	sum, err := checksum(rebuild)
+	if err != nil {
+		return false, nil, err
+	}
+
+	warnings := []string{}
+	if strings.HasSuffix(b.Stamp.Version, "-dirty") {
+		warnings = append(warnings, "working tree has uncommitted changes")
+	}
+
+	if !b.Stamp.Pinned {
+		warnings = append(warnings, "build time is taken from clock, commit the project or set SOURCE_DATE_EPOCH")
+	}
+
+	if b.Cgo {
+		warnings = append(warnings, "cgo is enabled, binary depends on host C toolchain")
+	}
+
+	if sum == artifact.Checksum {
+		return true, warnings, nil
+	}
+
+	warnings = append(warnings, fmt.Sprintf("rebuild checksum %s differs from %s", sum[:12], artifact.Checksum[:12]))
+
+	wd, _ := os.Getwd()
+	for _, binary := range []string{artifact.Binary, rebuild} {
+		content, err := os.ReadFile(binary)
+		if err == nil && bytes.Contains(content, []byte(wd)) {
+			warnings = append(warnings, fmt.Sprintf("%s embeds absolute path %s", filepath.Base(binary), wd))
+
+			break
+		}
+	}
+
+	first, _ := exec.Command("go", "version", "-m", artifact.Binary).Output()
+	second, _ := exec.Command("go", "version", "-m", rebuild).Output()
+	settings := map[string]bool{}
+	for _, line := range strings.Split(string(first), "\n")[1:] {
+		settings[strings.TrimSpace(line)] = true
+	}
+
+	for _, line := range strings.Split(string(second), "\n")[1:] {
+		if line = strings.TrimSpace(line); line != "" && !settings[line] {
+			warnings = append(warnings, fmt.Sprintf("build info differs: %s", line))
+		}
+	}
+
+	return false, warnings, nil
+}
+
+func checksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func compress(binary string, path string) error {
+	source, err := os.Open(binary)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	info, err := source.Stat()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	zipper := gzip.NewWriter(file)
+	archive := tar.NewWriter(zipper)
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+
+	if err := archive.WriteHeader(header); err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(archive, source); err != nil {
+		return err
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+
+	return zipper.Close()
+}
@@ -0,0 +1,79 @@
+package tool
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gertd/go-pluralize"
+	"github.com/iancoleman/strcase"
+)
+
+const cache = ".bima/cache"
+
+func save(manifest map[string]string) error {
+	content, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cache), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(cache, content, 0644)
+}
+
+func fingerprint(paths []string) string {
+	files := []string{}
+	missing := []string{}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			missing = append(missing, path)
+
+			continue
+		}
+
+		_ = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() {
+				files = append(files, file)
+			}
+
+			return nil
+		})
+	}
+
+	sort.Strings(files)
+
+	hasher := sha256.New()
+	for _, path := range missing {
+		fmt.Fprintf(hasher, "%s missing\n", filepath.ToSlash(path))
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		sum := sha256.Sum256(content)
+		fmt.Fprintf(hasher, "%s %x\n", filepath.ToSlash(file), sum)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+func folders() []string {
+	pluralizer := pluralize.NewClient()
+	folders := []string{}
+	for _, module := range Modules() {
+		folders = append(folders, strcase.ToDelimited(pluralizer.Plural(strcase.ToCamel(pluralizer.Singular(module))), '_'))
+	}
+
+	return folders
+}
@@ -0,0 +1,610 @@
+package tool
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bimalabs/framework/v4/configs"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v2"
+)
+
+type (
+	field struct {
+		key  string
+		path string
+		set  func(env *configs.Env, value string) error
+		get  func(env configs.Env) string
+	}
+
+	Config struct {
+		File string
+		temp bool
+	}
+
+	Problem struct {
+		File    string `json:"file"`
+		Key     string `json:"key,omitempty"`
+		Message string `json:"message"`
+	}
+)
+
+var fields = []field{
+	{
+		key:  "APP_DEBUG",
+		path: "debug",
+		set: func(env *configs.Env, value string) (err error) {
+			env.Debug, err = strconv.ParseBool(value)
+
+			return err
+		},
+		get: func(env configs.Env) string { return strconv.FormatBool(env.Debug) },
+	},
+	{
+		key:  "APP_PORT",
+		path: "http_port",
+		set: func(env *configs.Env, value string) (err error) {
+			env.HttpPort, err = strconv.Atoi(value)
+
+			return err
+		},
+		get: func(env configs.Env) string { return strconv.Itoa(env.HttpPort) },
+	},
+	{
+		key:  "GRPC_PORT",
+		path: "rpc_port",
+		set: func(env *configs.Env, value string) (err error) {
+			env.RpcPort, err = strconv.Atoi(value)
+
+			return err
+		},
+		get: func(env configs.Env) string { return strconv.Itoa(env.RpcPort) },
+	},
+	{
+		key:  "APP_NAME",
+		path: "service",
+		set: func(env *configs.Env, value string) error {
+			env.Service = value
+
+			return nil
+		},
+		get: func(env configs.Env) string { return env.Service },
+	},
+	{
+		key:  "APP_SECRET",
+		path: "secret",
+		set: func(env *configs.Env, value string) error {
+			env.Secret = value
+
+			return nil
+		},
+		get: func(env configs.Env) string { return env.Secret },
+	},
+	{
+		key:  "API_PREFIX",
+		path: "api_prefix",
+		set: func(env *configs.Env, value string) error {
+			env.ApiPrefix = value
+
+			return nil
+		},
+		get: func(env configs.Env) string { return env.ApiPrefix },
+	},
+	{
+		key:  "DB_HOST",
+		path: "database.host",
+		set: func(env *configs.Env, value string) error {
+			env.Db.Host = value
+
+			return nil
+		},
+		get: func(env configs.Env) string { return env.Db.Host },
+	},
+	{
+		key:  "DB_PORT",
+		path: "database.port",
+		set: func(env *configs.Env, value string) (err error) {
+			env.Db.Port, err = strconv.Atoi(value)
+
+			return err
+		},
+		get: func(env configs.Env) string { return strconv.Itoa(env.Db.Port) },
+	},
+	{
+		key:  "DB_USER",
+		path: "database.user",
+		set: func(env *configs.Env, value string) error {
+			env.Db.User = value
+
+			return nil
+		},
+		get: func(env configs.Env) string { return env.Db.User },
+	},
+	{
+		key:  "DB_PASSWORD",
+		path: "database.password",
+		set: func(env *configs.Env, value string) error {
+			env.Db.Password = value
+
+			return nil
+		},
+		get: func(env configs.Env) string { return env.Db.Password },
+	},
+	{
+		key:  "DB_NAME",
+		path: "database.name",
+		set: func(env *configs.Env, value string) error {
+			env.Db.Name = value
+
+			return nil
+		},
+		get: func(env configs.Env) string { return env.Db.Name },
+	},
+	{
+		key:  "DB_DRIVER",
+		path: "database.driver",
+		set: func(env *configs.Env, value string) error {
+			env.Db.Driver = value
+
+			return nil
+		},
+		get: func(env configs.Env) string { return env.Db.Driver },
+	},
+	{
+		key:  "CACHE_LIFETIME",
+		path: "cache_lifetime",
+		set: func(env *configs.Env, value string) (err error) {
+			env.CacheLifetime, err = strconv.Atoi(value)
+
+			return err
+		},
+		get: func(env configs.Env) string { return strconv.Itoa(env.CacheLifetime) },
+	},
+}
+
+func Load(files []string, overrides []string) (configs.Env, error) {
+	env := configs.Env{}
+	for _, file := range files {
+		if err := load(&env, file); err != nil {
+			return env, err
+		}
+	}
+
+	values := map[string]string{}
+	for _, v := range overrides {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok {
+			return env, fmt.Errorf("invalid override %s, use KEY=VALUE format", v)
+		}
+
+		values[strings.TrimSpace(key)] = value
+	}
+
+	for key := range values {
+		if lookup(key) == nil {
+			return env, fmt.Errorf("unknown config key %s", key)
+		}
+	}
+
+	return env, apply(&env, values)
+}
+
+func Materialize(files []string, overrides []string) (Config, error) {
+	if len(files) == 1 && len(overrides) == 0 && !Encrypted(files[0]) {
+		return Config{File: files[0]}, nil
+	}
+
+	values := map[string]string{}
+	secrets := map[string]bool{}
+	for _, file := range files {
+		if err := collect(file, values, secrets); err != nil {
+			return Config{}, err
+		}
+	}
+
+	for _, v := range overrides {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok {
+			return Config{}, fmt.Errorf("invalid override %s, use KEY=VALUE format", v)
+		}
+
+		key = strings.TrimSpace(key)
+		if lookup(key) == nil {
+			return Config{}, fmt.Errorf("unknown config key %s", key)
+		}
+
+		values[key] = value
+		secrets[key] = false
+	}
+
+	if err := apply(&configs.Env{}, values); err != nil {
+		return Config{}, err
+	}
+
+	entries := []entry{}
+	for _, f := range fields {
+		value, ok := values[f.key]
+		if !ok {
+			continue
+		}
+
+		if err := os.Setenv(f.key, value); err != nil {
+			return Config{}, err
+		}
+
+		if !secrets[f.key] {
+			entries = append(entries, entry{path: f.path, value: value})
+		}
+	}
+
+	temp, err := os.CreateTemp("", "bima-config-*.env")
+	if err != nil {
+		return Config{}, err
+	}
+	defer temp.Close()
+
+	config := Config{File: temp.Name(), temp: true}
+	if _, err := temp.Write(flatten(entries)); err != nil {
+		_ = config.Close()
+
+		return Config{}, err
+	}
+
+	return config, nil
+}
+
+func (c Config) Close() error {
+	if !c.temp {
+		return nil
+	}
+
+	return os.Remove(c.File)
+}
+
+func collect(file string, values map[string]string, secrets map[string]bool) error {
+	raw := map[string]string{}
+	if Format(file) == "env" {
+		content, err := godotenv.Read(file)
+		if err != nil {
+			return err
+		}
+
+		raw = content
+	} else {
+		entries, err := read(file)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range entries {
+			for _, f := range fields {
+				if f.path != e.path {
+					continue
+				}
+
+				raw[f.key] = ""
+				if e.value != nil {
+					raw[f.key] = fmt.Sprint(e.value)
+				}
+			}
+		}
+	}
+
+	for _, f := range fields {
+		value, ok := raw[f.key]
+		if !ok {
+			continue
+		}
+
+		plain, err := reveal(value)
+		if err != nil {
+			return fmt.Errorf("%s: %s", f.key, err.Error())
+		}
+
+		values[f.key] = plain
+		secrets[f.key] = encrypted.MatchString(value)
+	}
+
+	return nil
+}
+
+func Format(path string) string {
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".json":
+		return "json"
+	}
+
+	return "env"
+}
+
+func config(env *configs.Env, path string) error {
+	if err := load(env, path); err != nil && !os.IsNotExist(err) {
+		return Fail(ExitEnvironment, err)
+	}
+
+	return nil
+}
+
+func load(env *configs.Env, path string) error {
+	format := Format(path)
+	if format == "env" {
+		values, err := godotenv.Read(path)
+		if err != nil {
+			return err
+		}
+
+		for key, value := range values {
+			if values[key], err = reveal(value); err != nil {
+				return fmt.Errorf("%s: %s", key, err.Error())
+			}
+		}
+
+		return apply(env, values)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	content, err = decrypt(content, format)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		return json.Unmarshal(content, env)
+	}
+
+	return yaml.Unmarshal(content, env)
+}
+
+func apply(env *configs.Env, values map[string]string) error {
+	for _, f := range fields {
+		value, ok := values[f.key]
+		if !ok {
+			continue
+		}
+
+		if err := f.set(env, strings.TrimSpace(value)); err != nil && strings.TrimSpace(value) != "" {
+			return fmt.Errorf("invalid value %q for %s", value, f.key)
+		}
+	}
+
+	return nil
+}
+
+func lookupPath(path string) *field {
+	for k, f := range fields {
+		if f.path == path {
+			return &fields[k]
+		}
+	}
+
+	return nil
+}
+
+func lookup(key string) *field {
+	for k, f := range fields {
+		if f.key == key {
+			return &fields[k]
+		}
+	}
+
+	return nil
+}
+
+func Validate(files []string) []Problem {
+	problems := []Problem{}
+	env := configs.Env{}
+	for _, file := range files {
+		problems = append(problems, inspect(&env, file)...)
+	}
+
+	source := strings.Join(files, ", ")
+	name := func(key string) string {
+		if len(files) == 0 || Format(files[len(files)-1]) == "env" {
+			return key
+		}
+
+		return lookup(key).path
+	}
+
+	ports := map[string]int{"APP_PORT": env.HttpPort, "GRPC_PORT": env.RpcPort}
+	for _, key := range []string{"APP_PORT", "GRPC_PORT"} {
+		port := ports[key]
+		if port == 0 {
+			problems = append(problems, Problem{File: source, Key: name(key), Message: "value is required"})
+
+			continue
+		}
+
+		if port < 1 || port > 65535 {
+			problems = append(problems, Problem{File: source, Key: name(key), Message: fmt.Sprintf("port %d is out of range", port)})
+		}
+	}
+
+	if env.HttpPort != 0 && env.HttpPort == env.RpcPort {
+		problems = append(problems, Problem{File: source, Key: name("GRPC_PORT"), Message: "must be different from APP_PORT"})
+	}
+
+	if env.Secret == "" {
+		problems = append(problems, Problem{File: source, Key: name("APP_SECRET"), Message: "value is required"})
+	}
+
+	if !registered() {
+		return problems
+	}
+
+	required := map[string]string{"DB_DRIVER": env.Db.Driver, "DB_HOST": env.Db.Host, "DB_NAME": env.Db.Name}
+	for _, key := range []string{"DB_DRIVER", "DB_HOST", "DB_NAME"} {
+		if required[key] == "" {
+			problems = append(problems, Problem{File: source, Key: name(key), Message: "value is required when modules are registered"})
+		}
+	}
+
+	if env.Db.Port < 0 || env.Db.Port > 65535 {
+		problems = append(problems, Problem{File: source, Key: name("DB_PORT"), Message: fmt.Sprintf("port %d is out of range", env.Db.Port)})
+	}
+
+	return problems
+}
+
+func inspect(env *configs.Env, file string) []Problem {
+	problems := []Problem{}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return append(problems, Problem{File: file, Message: err.Error()})
+	}
+
+	if content, err = decrypt(content, Format(file)); err != nil {
+		return append(problems, Problem{File: file, Message: err.Error()})
+	}
+
+	switch Format(file) {
+	case "yaml":
+		values := map[string]interface{}{}
+		if err := yaml.Unmarshal(content, &values); err != nil {
+			return append(problems, Problem{File: file, Message: err.Error()})
+		}
+
+		for _, key := range unknown(values, "yaml") {
+			problems = append(problems, Problem{File: file, Key: key, Message: "unknown key"})
+		}
+
+		if err := yaml.Unmarshal(content, env); err != nil {
+			if typed, ok := err.(*yaml.TypeError); ok {
+				for _, message := range typed.Errors {
+					problems = append(problems, Problem{File: file, Message: message})
+				}
+			} else {
+				problems = append(problems, Problem{File: file, Message: err.Error()})
+			}
+		}
+	case "json":
+		values := map[string]interface{}{}
+		if err := json.Unmarshal(content, &values); err != nil {
+			return append(problems, Problem{File: file, Message: err.Error()})
+		}
+
+		for _, key := range unknown(values, "json") {
+			problems = append(problems, Problem{File: file, Key: key, Message: "unknown key"})
+		}
+
+		if err := json.Unmarshal(content, env); err != nil {
+			if typed, ok := err.(*json.UnmarshalTypeError); ok {
+				problems = append(problems, Problem{File: file, Key: typed.Field, Message: fmt.Sprintf("expect %s but got %s", typed.Type, typed.Value)})
+			} else {
+				problems = append(problems, Problem{File: file, Message: err.Error()})
+			}
+		}
+	default:
+		values, err := godotenv.Read(file)
+		if err != nil {
+			return append(problems, Problem{File: file, Message: err.Error()})
+		}
+
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+		for _, key := range keys {
+			f := lookup(key)
+			if f == nil {
+				problems = append(problems, Problem{File: file, Key: key, Message: "unknown key"})
+
+				continue
+			}
+
+			value, err := reveal(values[key])
+			if err != nil {
+				problems = append(problems, Problem{File: file, Key: key, Message: err.Error()})
+
+				continue
+			}
+
+			if err := f.set(env, strings.TrimSpace(value)); err != nil && strings.TrimSpace(value) != "" {
+				problems = append(problems, Problem{File: file, Key: key, Message: fmt.Sprintf("invalid value %q", values[key])})
+			}
+		}
+	}
+
+	return problems
+}
+
+func unknown(values map[string]interface{}, tag string) []string {
+	keys := []string{}
+	known := names(reflect.TypeOf(configs.Env{}), tag)
+	for key, value := range values {
+		children, ok := known[key]
+		if !ok {
+			keys = append(keys, key)
+
+			continue
+		}
+
+		if children == nil {
+			continue
+		}
+
+		nested := []string{}
+		switch items := value.(type) {
+		case map[string]interface{}:
+			for k := range items {
+				nested = append(nested, k)
+			}
+		case map[interface{}]interface{}:
+			for k := range items {
+				nested = append(nested, fmt.Sprint(k))
+			}
+		}
+
+		for _, k := range nested {
+			if !children[k] {
+				keys = append(keys, fmt.Sprintf("%s.%s", key, k))
+			}
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func names(t reflect.Type, tag string) map[string]map[string]bool {
+	known := map[string]map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get(tag), ",")[0]
+		if name == "" {
+			name = f.Name
+			if tag == "yaml" {
+				name = strings.ToLower(name)
+			}
+		}
+
+		known[name] = nil
+		if f.Type.Kind() == reflect.Struct {
+			known[name] = map[string]bool{}
+			for child := range names(f.Type, tag) {
+				known[name][child] = true
+			}
+		}
+	}
+
+	return known
+}
+
+func registered() bool {
+	return len(Modules()) > 0
+}
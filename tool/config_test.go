@@ -0,0 +1,166 @@
+package tool
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bimalabs/framework/v4/configs"
+)
+
+func write(t *testing.T, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestApply(t *testing.T) {
+	cases := []struct {
+		name   string
+		values map[string]string
+		expect configs.Env
+		fail   bool
+	}{
+		{name: "empty", values: map[string]string{}, expect: configs.Env{}},
+		{name: "numbers", values: map[string]string{"APP_PORT": "7777", "DB_PORT": " 5432 "}, expect: configs.Env{HttpPort: 7777, Db: configs.Db{Port: 5432}}},
+		{name: "empty number", values: map[string]string{"DB_PORT": "", "GRPC_PORT": " "}, expect: configs.Env{}},
+		{name: "empty bool", values: map[string]string{"APP_DEBUG": ""}, expect: configs.Env{}},
+		{name: "bool", values: map[string]string{"APP_DEBUG": "true"}, expect: configs.Env{Debug: true}},
+		{name: "string", values: map[string]string{"APP_NAME": "bima", "DB_PASSWORD": ""}, expect: configs.Env{Service: "bima"}},
+		{name: "invalid number", values: map[string]string{"APP_PORT": "abc"}, fail: true},
+		{name: "invalid bool", values: map[string]string{"APP_DEBUG": "maybe"}, fail: true},
+		{name: "unknown key", values: map[string]string{"UNKNOWN": "x"}, expect: configs.Env{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			env := configs.Env{}
+			err := apply(&env, c.values)
+			if c.fail {
+				if err == nil {
+					t.Fatalf("expect error, got %+v", env)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if env != c.expect {
+				t.Fatalf("expect %+v, got %+v", c.expect, env)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	base := write(t, ".env", "APP_PORT=7777\nGRPC_PORT=1717\nDB_PORT=\nAPP_NAME=base\n")
+	layer := write(t, "local.yaml", "rpc_port: 2727\ndatabase:\n  host: localhost\n")
+	document := write(t, "test.json", `{"service": "json"}`)
+
+	cases := []struct {
+		name      string
+		files     []string
+		overrides []string
+		expect    configs.Env
+		fail      bool
+	}{
+		{name: "env", files: []string{base}, expect: configs.Env{HttpPort: 7777, RpcPort: 1717, Service: "base"}},
+		{name: "layered", files: []string{base, layer}, expect: configs.Env{HttpPort: 7777, RpcPort: 2727, Service: "base", Db: configs.Db{Host: "localhost"}}},
+		{name: "json", files: []string{base, document}, expect: configs.Env{HttpPort: 7777, RpcPort: 1717, Service: "json"}},
+		{name: "override", files: []string{base, layer}, overrides: []string{"APP_PORT=8888", "DB_HOST=db"}, expect: configs.Env{HttpPort: 8888, RpcPort: 2727, Service: "base", Db: configs.Db{Host: "db"}}},
+		{name: "invalid override", files: []string{base}, overrides: []string{"APP_PORT"}, fail: true},
+		{name: "unknown override", files: []string{base}, overrides: []string{"UNKNOWN=1"}, fail: true},
+		{name: "missing file", files: []string{filepath.Join(t.TempDir(), "missing.env")}, fail: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			env, err := Load(c.files, c.overrides)
+			if c.fail {
+				if err == nil {
+					t.Fatalf("expect error, got %+v", env)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if env != c.expect {
+				t.Fatalf("expect %+v, got %+v", c.expect, env)
+			}
+		})
+	}
+}
+
+func TestMaterialize(t *testing.T) {
+	base := write(t, ".env", "APP_PORT=7777\nAPP_NAME=base\n")
+	layer := write(t, "local.yaml", "rpc_port: 2727\n")
+
+	for _, key := range []string{"APP_PORT", "APP_NAME", "GRPC_PORT", "DB_HOST", "DB_PORT"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+
+	single, err := Materialize([]string{base}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if single.File != base || single.Close() != nil {
+		t.Fatalf("expect single config %s to be used as is, got %s", base, single.File)
+	}
+
+	config, err := Materialize([]string{base, layer}, []string{"DB_HOST=db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.HasPrefix(config.File, ".bima") {
+		t.Fatalf("expect temporary config, got %s", config.File)
+	}
+
+	env := configs.Env{}
+	if err := load(&env, config.File); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := configs.Env{HttpPort: 7777, RpcPort: 2727, Service: "base", Db: configs.Db{Host: "db"}}
+	if env != expect {
+		t.Fatalf("expect %+v, got %+v", expect, env)
+	}
+
+	exported := map[string]string{"APP_PORT": "7777", "APP_NAME": "base", "GRPC_PORT": "2727", "DB_HOST": "db"}
+	for key, value := range exported {
+		if os.Getenv(key) != value {
+			t.Fatalf("expect %s to be exported as %s, got %q", key, value, os.Getenv(key))
+		}
+	}
+
+	if _, ok := os.LookupEnv("DB_PORT"); ok {
+		t.Fatal("expect unset DB_PORT not to be exported")
+	}
+
+	if err := config.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(config.File); !os.IsNotExist(err) {
+		t.Fatalf("expect %s to be removed on close", config.File)
+	}
+
+	if _, err := Materialize([]string{base}, []string{"APP_PORT=abc"}); err == nil {
+		t.Fatal("expect invalid override to fail")
+	}
+}
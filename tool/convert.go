@@ -0,0 +1,308 @@
+package tool
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bimalabs/framework/v4/configs"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v2"
+)
+
+type entry struct {
+	path  string
+	value interface{}
+}
+
+var plain = regexp.MustCompile(`^[A-Za-z0-9_./:@+-]*$`)
+
+func Convert(from string, to string, force bool) error {
+	if from == to {
+		return errors.New("source and target config must be different")
+	}
+
+	if _, err := os.Stat(to); err == nil && !force {
+		return fmt.Errorf("%s already exists, use --force to overwrite", to)
+	}
+
+	entries, err := read(from)
+	if err != nil {
+		return err
+	}
+
+	var content []byte
+	switch Format(to) {
+	case "yaml":
+		content, err = yaml.Marshal(nest(entries))
+	case "json":
+		content, err = ordered(nest(entries))
+	default:
+		content = flatten(entries)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(to, content, 0644)
+}
+
+func References(from string, to string) ([]string, error) {
+	candidates := []string{"Taskfile.yml", "Taskfile.yaml", "docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml"}
+	dockerfiles, _ := filepath.Glob("Dockerfile*")
+	candidates = append(dockerfiles, candidates...)
+
+	updated := []string{}
+	for _, file := range candidates {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		replaced := relink(content, from, to)
+		if bytes.Equal(replaced, content) {
+			continue
+		}
+
+		if err := os.WriteFile(file, replaced, 0644); err != nil {
+			return updated, err
+		}
+
+		updated = append(updated, file)
+	}
+
+	return updated, nil
+}
+
+func relink(content []byte, from string, to string) []byte {
+	pattern := regexp.MustCompile(fmt.Sprintf(`(^|[\s"'=/:])%s`, regexp.QuoteMeta(from)))
+	boundary := regexp.MustCompile(`^([\s"'/]|$)`)
+
+	var result bytes.Buffer
+	last := 0
+	for _, match := range pattern.FindAllSubmatchIndex(content, -1) {
+		if !boundary.Match(content[match[1]:]) {
+			continue
+		}
+
+		result.Write(content[last:match[3]])
+		result.WriteString(to)
+		last = match[1]
+	}
+
+	result.Write(content[last:])
+
+	return result.Bytes()
+}
+
+func read(file string) ([]entry, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []entry{}
+	switch Format(file) {
+	case "yaml":
+		values := yaml.MapSlice{}
+		if err := yaml.Unmarshal(content, &values); err != nil {
+			return nil, err
+		}
+
+		return walk(entries, "", values), nil
+	case "json":
+		values := map[string]interface{}{}
+		if err := json.Unmarshal(content, &values); err != nil {
+			return nil, err
+		}
+
+		return walk(entries, "", values), nil
+	}
+
+	values, err := godotenv.Read(file)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range fields {
+		value, ok := values[f.key]
+		if !ok {
+			continue
+		}
+
+		env := configs.Env{}
+		if err := f.set(&env, strings.TrimSpace(value)); err != nil && strings.TrimSpace(value) != "" {
+			return nil, fmt.Errorf("invalid value %q for %s", value, f.key)
+		}
+
+		entries = append(entries, entry{path: f.path, value: valueOf(env, f.path)})
+		delete(values, f.key)
+	}
+
+	extras := make([]string, 0, len(values))
+	for key := range values {
+		extras = append(extras, key)
+	}
+
+	sort.Strings(extras)
+	for _, key := range extras {
+		entries = append(entries, entry{path: key, value: values[key]})
+	}
+
+	return entries, nil
+}
+
+func walk(entries []entry, prefix string, values interface{}) []entry {
+	switch items := values.(type) {
+	case yaml.MapSlice:
+		for _, v := range items {
+			entries = walk(entries, join(prefix, fmt.Sprint(v.Key)), v.Value)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(items))
+		for k := range items {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+		for _, k := range keys {
+			entries = walk(entries, join(prefix, k), items[k])
+		}
+	default:
+		if number, ok := values.(float64); ok && number == float64(int64(number)) {
+			values = int64(number)
+		}
+
+		entries = append(entries, entry{path: prefix, value: values})
+	}
+
+	return entries
+}
+
+func nest(entries []entry) yaml.MapSlice {
+	root := yaml.MapSlice{}
+	for _, e := range entries {
+		root = insert(root, strings.Split(e.path, "."), e.value)
+	}
+
+	return root
+}
+
+func insert(values yaml.MapSlice, path []string, value interface{}) yaml.MapSlice {
+	if len(path) == 1 {
+		return replace(values, path[0], value)
+	}
+
+	for k, v := range values {
+		if v.Key == path[0] {
+			child, _ := v.Value.(yaml.MapSlice)
+			values[k].Value = insert(child, path[1:], value)
+
+			return values
+		}
+	}
+
+	return append(values, yaml.MapItem{Key: path[0], Value: insert(yaml.MapSlice{}, path[1:], value)})
+}
+
+func ordered(values yaml.MapSlice) ([]byte, error) {
+	var content bytes.Buffer
+
+	content.WriteString("{")
+	for k, v := range values {
+		if k > 0 {
+			content.WriteString(",")
+		}
+
+		key, _ := json.Marshal(fmt.Sprint(v.Key))
+		content.Write(key)
+		content.WriteString(":")
+
+		var (
+			value []byte
+			err   error
+		)
+		if child, ok := v.Value.(yaml.MapSlice); ok {
+			value, err = ordered(child)
+		} else {
+			value, err = json.Marshal(v.Value)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		content.Write(value)
+	}
+
+	content.WriteString("}")
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, content.Bytes(), "", "    "); err != nil {
+		return nil, err
+	}
+
+	indented.WriteString("\n")
+
+	return indented.Bytes(), nil
+}
+
+func flatten(entries []entry) []byte {
+	var content strings.Builder
+	for _, e := range entries {
+		key := strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(e.path))
+		for _, f := range fields {
+			if f.path == e.path {
+				key = f.key
+
+				break
+			}
+		}
+
+		value := fmt.Sprint(e.value)
+		if e.value == nil {
+			value = ""
+		}
+
+		if !plain.MatchString(value) {
+			value = strconv.Quote(value)
+		}
+
+		content.WriteString(fmt.Sprintf("%s=%s\n", key, value))
+	}
+
+	return []byte(content.String())
+}
+
+func valueOf(env configs.Env, path string) interface{} {
+	value := reflect.ValueOf(env)
+	for _, name := range strings.Split(path, ".") {
+		t := value.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0] == name {
+				value = value.Field(i)
+
+				break
+			}
+		}
+	}
+
+	return value.Interface()
+}
+
+func join(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return fmt.Sprintf("%s.%s", prefix, key)
+}
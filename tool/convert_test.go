@@ -0,0 +1,94 @@
+package tool
+
+import (
+	"os"
+	"testing"
+)
+
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+}
+
+func TestRelink(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		from    string
+		to      string
+		expect  string
+	}{
+		{name: "argument", content: "CMD [\"/app/bima\", \"run\", \".env\"]", from: ".env", to: "app.yaml", expect: "CMD [\"/app/bima\", \"run\", \"app.yaml\"]"},
+		{name: "target contains source", content: "cmds:\n  - bima run app.env\n", from: "app.env", to: "configs/app.env", expect: "cmds:\n  - bima run configs/app.env\n"},
+		{name: "adjacent", content: "app.env app.env", from: "app.env", to: "app.yaml", expect: "app.yaml app.yaml"},
+		{name: "path", content: "COPY ./app.env /app/app.env", from: "app.env", to: "app.json", expect: "COPY ./app.json /app/app.json"},
+		{name: "assignment", content: "env_file=app.env\n", from: "app.env", to: "app.yaml", expect: "env_file=app.yaml\n"},
+		{name: "longer name", content: "bima run myapp.env app.env.bak", from: "app.env", to: "app.yaml", expect: "bima run myapp.env app.env.bak"},
+		{name: "start and end", content: "app.env", from: "app.env", to: "app.yaml", expect: "app.yaml"},
+		{name: "unchanged", content: "nothing here", from: "app.env", to: "app.yaml", expect: "nothing here"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if result := string(relink([]byte(c.content), c.from, c.to)); result != c.expect {
+				t.Fatalf("expect %q, got %q", c.expect, result)
+			}
+		})
+	}
+}
+
+func TestReferences(t *testing.T) {
+	chdir(t, t.TempDir())
+
+	files := map[string]string{
+		"Dockerfile":   "COPY app.env /app/app.env\n",
+		"Taskfile.yml": "cmds:\n  - bima run -c app.env\n",
+		"compose.yml":  "services: {}\n",
+		"README.md":    "bima run -c app.env\n",
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	updated, err := References("app.env", "configs/app.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(updated) != 2 || updated[0] != "Dockerfile" || updated[1] != "Taskfile.yml" {
+		t.Fatalf("expect Dockerfile and Taskfile.yml to be updated, got %v", updated)
+	}
+
+	expect := map[string]string{
+		"Dockerfile":   "COPY configs/app.env /app/configs/app.env\n",
+		"Taskfile.yml": "cmds:\n  - bima run -c configs/app.env\n",
+		"compose.yml":  files["compose.yml"],
+		"README.md":    files["README.md"],
+	}
+
+	for name, content := range expect {
+		result, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(result) != content {
+			t.Fatalf("expect %s to be %q, got %q", name, content, result)
+		}
+	}
+}
@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/bimalabs/cli/bima"
-	"github.com/briandowns/spinner"
 	"github.com/fatih/color"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -144,14 +143,15 @@ func (a App) Create() error {
 		util.Print(string(a))
 		fmt.Print(" folder and type ")
 		util.Println("bima run")
+
+		return Emit(map[string]string{"type": "app", "name": string(a), "dir": fmt.Sprintf("%s/%s", wd, string(a))})
 	}
 
 	return err
 }
 
 func (m Middleware) Create() error {
-	progress := spinner.New(spinner.CharSets[bima.SpinerIndex], bima.Duration)
-	progress.Suffix = " Creating middleware... "
+	progress := Spinner(" Creating middleware... ")
 	progress.Start()
 	time.Sleep(1 * time.Second)
 
@@ -187,9 +187,8 @@ func (m Middleware) Create() error {
 	_ = f.Sync()
 	_ = f.Close()
 
-	if err := Call("clean"); err != nil {
+	if err := NewPipeline(false).Run(Clean()); err != nil {
 		progress.Stop()
-		color.New(color.FgRed).Println("Error cleaning dependencies")
 
 		return err
 	}
@@ -197,12 +196,11 @@ func (m Middleware) Create() error {
 	progress.Stop()
 	fmt.Printf("Middleware %s has been created\n", color.New(color.FgGreen).Sprint(name))
 
-	return nil
+	return Emit(map[string]string{"type": "middleware", "name": name, "file": fmt.Sprintf("%s/middlewares/%s.go", wd, strings.ToLower(string(m)))})
 }
 
 func (d Driver) Create() error {
-	progress := spinner.New(spinner.CharSets[bima.SpinerIndex], bima.Duration)
-	progress.Suffix = " Creating database driver... "
+	progress := Spinner(" Creating database driver... ")
 	progress.Start()
 	time.Sleep(1 * time.Second)
 
@@ -238,9 +236,8 @@ func (d Driver) Create() error {
 	_ = f.Sync()
 	_ = f.Close()
 
-	if err := Call("clean"); err != nil {
+	if err := NewPipeline(false).Run(Clean()); err != nil {
 		progress.Stop()
-		color.New(color.FgRed).Println("Error cleaning dependencies")
 
 		return err
 	}
@@ -248,12 +245,11 @@ func (d Driver) Create() error {
 	progress.Stop()
 	fmt.Printf("Driver %s has been created\n", color.New(color.FgGreen).Sprint(name))
 
-	return nil
+	return Emit(map[string]string{"type": "driver", "name": name, "file": fmt.Sprintf("%s/drivers/%s.go", wd, strings.ToLower(string(d)))})
 }
 
 func (a Adapter) Create() error {
-	progress := spinner.New(spinner.CharSets[bima.SpinerIndex], bima.Duration)
-	progress.Suffix = " Creating pagination adapter... "
+	progress := Spinner(" Creating pagination adapter... ")
 	progress.Start()
 	time.Sleep(1 * time.Second)
 
@@ -289,23 +285,20 @@ func (a Adapter) Create() error {
 	_ = f.Sync()
 	_ = f.Close()
 
-	if err := Call("clean"); err != nil {
+	if err := NewPipeline(false).Run(Clean()); err != nil {
 		progress.Stop()
 
-		color.New(color.FgRed).Println("Error cleaning dependencies")
-
 		return err
 	}
 
 	progress.Stop()
 	fmt.Printf("Adapter %s has been created\n", color.New(color.FgGreen).Sprint(name))
 
-	return nil
+	return Emit(map[string]string{"type": "adapter", "name": name, "file": fmt.Sprintf("%s/adapters/%s.go", wd, strings.ToLower(string(a)))})
 }
 
 func (r Route) Create() error {
-	progress := spinner.New(spinner.CharSets[bima.SpinerIndex], bima.Duration)
-	progress.Suffix = " Creating route placeholder... "
+	progress := Spinner(" Creating route placeholder... ")
 	progress.Start()
 	time.Sleep(1 * time.Second)
 
@@ -342,26 +335,23 @@ func (r Route) Create() error {
 	_ = f.Sync()
 	_ = f.Close()
 
-	if err := Call("clean"); err != nil {
+	if err := NewPipeline(false).Run(Clean()); err != nil {
 		progress.Stop()
 
-		color.New(color.FgRed).Println("Error cleaning dependencies")
-
 		return err
 	}
 
 	progress.Stop()
 	fmt.Printf("Route %s has been created\n", color.New(color.FgGreen).Sprint(name))
 
-	return nil
+	return Emit(map[string]string{"type": "route", "name": name, "file": fmt.Sprintf("%s/routes/%s.go", wd, lName)})
 }
 
 func createApp(name string) error {
-	progress := spinner.New(spinner.CharSets[bima.SpinerIndex], bima.Duration)
-	progress.Suffix = fmt.Sprintf(" Creating %s project... ", color.New(color.FgGreen).Sprint(name))
+	progress := Spinner(fmt.Sprintf(" Creating %s project... ", color.New(color.FgGreen).Sprint(name)))
 	progress.Start()
 
-	output, err := exec.Command("git", "clone", "--depth", "1", "https://github.com/bimalabs/skeleton.git", name).CombinedOutput()
+	output, err := call(exec.Command("git", "clone", "--depth", "1", "https://github.com/bimalabs/skeleton.git", name))
 	if err != nil {
 		progress.Stop()
 		color.New(color.FgRed).Println(string(output))
@@ -374,7 +364,7 @@ func createApp(name string) error {
 
 	cmd := exec.Command("git", "fetch", "--tags")
 	cmd.Dir = dir
-	output, err = cmd.CombinedOutput()
+	output, err = call(cmd)
 	if err != nil {
 		progress.Stop()
 		color.New(color.FgRed).Println(string(output))
@@ -385,7 +375,7 @@ func createApp(name string) error {
 
 	cmd = exec.Command("git", "checkout", bima.SkeletonVersion)
 	cmd.Dir = dir
-	output, err = cmd.CombinedOutput()
+	output, err = call(cmd)
 	if err != nil {
 		progress.Stop()
 		color.New(color.FgRed).Println(string(output))
@@ -394,7 +384,7 @@ func createApp(name string) error {
 		return err
 	}
 
-	output, err = exec.Command("rm", "-rf", fmt.Sprintf("%s/.git", name)).CombinedOutput()
+	output, err = call(exec.Command("rm", "-rf", fmt.Sprintf("%s/.git", name)))
 	if err != nil {
 		progress.Stop()
 		color.New(color.FgRed).Println(string(output))
@@ -429,17 +419,16 @@ func createApp(name string) error {
 
 	progress.Stop()
 
-	progress = spinner.New(spinner.CharSets[bima.SpinerIndex], bima.Duration)
-	progress.Suffix = " Downloading dependencies... "
+	progress = Spinner(" Downloading dependencies... ")
 	progress.Start()
 
 	cmd = exec.Command("go", "mod", "download")
 	cmd.Dir = fmt.Sprintf("%s/%s", wd, name)
-	_ = cmd.Run()
+	_, _ = call(cmd)
 
 	cmd = exec.Command("go", "run", "dumper/main.go")
 	cmd.Dir = dir
-	output, err = cmd.CombinedOutput()
+	output, err = call(cmd)
 	if err != nil {
 		progress.Stop()
 		color.New(color.FgRed).Println(string(output))
@@ -448,13 +437,12 @@ func createApp(name string) error {
 		return err
 	}
 
-	progress = spinner.New(spinner.CharSets[bima.SpinerIndex], bima.Duration)
-	progress.Suffix = " Cleaning project... "
+	progress = Spinner(" Cleaning project... ")
 	progress.Start()
 
 	cmd = exec.Command("go", "get")
 	cmd.Dir = dir
-	output, err = cmd.CombinedOutput()
+	output, err = call(cmd)
 	if err != nil {
 		progress.Stop()
 		color.New(color.FgRed).Println(string(output))
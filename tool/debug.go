@@ -0,0 +1,139 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"mvdan.cc/sh/interp"
+	"mvdan.cc/sh/syntax"
+)
+
+type Debugger struct {
+	Listen      string
+	Continue    bool
+	MultiClient bool
+	Launch      bool
+}
+
+func WaitPid(timeout time.Duration) (int, error) {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if pid := Pid(); pid != 0 {
+			return pid, nil
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return 0, fmt.Errorf("application does not write .pid file after %s", timeout)
+}
+
+func (d Debugger) Attach(ctx context.Context, pid int) error {
+	return d.dlv(ctx, fmt.Sprintf("dlv attach %d %s", pid, d.options()))
+}
+
+func (d Debugger) Exec(ctx context.Context, binary string, file string) error {
+	return d.dlv(ctx, fmt.Sprintf("dlv exec %s %s -- run %s", binary, d.options(), file))
+}
+
+func (d Debugger) Configure() error {
+	host, value, err := net.SplitHostPort(d.Listen)
+	if err != nil {
+		return err
+	}
+
+	port, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+
+	if host == "" || host == "0.0.0.0" {
+		host = "127.0.0.1"
+	}
+
+	attach := map[string]interface{}{
+		"name":    "Attach Bima",
+		"type":    "go",
+		"request": "attach",
+		"mode":    "remote",
+		"host":    host,
+		"port":    port,
+	}
+
+	launch := map[string]interface{}{
+		"version":        "0.2.0",
+		"configurations": []interface{}{attach},
+	}
+
+	path := ".vscode/launch.json"
+	content, err := os.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(content, &launch); err != nil {
+			snippet, _ := json.MarshalIndent(attach, "", "    ")
+			color.New(color.FgYellow).Printf("Unable to update %s, add this configuration manually:\n", path)
+			fmt.Println(string(snippet))
+
+			return nil
+		}
+
+		configurations, _ := launch["configurations"].([]interface{})
+		replaced := false
+		for k, v := range configurations {
+			if c, ok := v.(map[string]interface{}); ok && c["name"] == attach["name"] {
+				configurations[k] = attach
+				replaced = true
+			}
+		}
+
+		if !replaced {
+			configurations = append(configurations, attach)
+		}
+
+		launch["configurations"] = configurations
+	}
+
+	content, err = json.MarshalIndent(launch, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(".vscode", 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(content, '\n'), 0644)
+}
+
+func (d Debugger) options() string {
+	var options strings.Builder
+
+	options.WriteString(fmt.Sprintf("--listen=%s --headless --api-version=2 --log", d.Listen))
+	if d.Continue {
+		options.WriteString(" --continue")
+	}
+
+	if d.MultiClient {
+		options.WriteString(" --accept-multiclient")
+	}
+
+	return options.String()
+}
+
+func (d Debugger) dlv(ctx context.Context, line string) error {
+	if d.Listen == "" {
+		return errors.New("debugger listen address is required")
+	}
+
+	cmd, _ := syntax.NewParser().Parse(strings.NewReader(line), "")
+	runner, _ := interp.New(interp.Env(nil), interp.StdIO(nil, os.Stdout, os.Stdout))
+
+	return runner.Run(ctx, cmd)
+}
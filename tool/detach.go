@@ -0,0 +1,13 @@
+//go:build !windows
+// +build !windows
+
+package tool
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
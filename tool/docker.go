@@ -0,0 +1,435 @@
+package tool
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bimalabs/framework/v4/configs"
+	"golang.org/x/mod/modfile"
+)
+
+const dockerfile = `FROM golang:%s-alpine AS builder
+
+WORKDIR /src
+COPY go.mod go.sum ./
+RUN go mod download
+
+COPY . .
+RUN CGO_ENABLED=0 go build -trimpath -ldflags "-s -w" -o /out/bima cmd/main.go
+RUN printf '%s' > /tmp/healthcheck.go && CGO_ENABLED=0 go build -trimpath -ldflags "-s -w" -o /out/healthcheck /tmp/healthcheck.go
+
+FROM gcr.io/distroless/static-debian12:nonroot
+
+WORKDIR /app
+COPY --from=builder /out/bima /app/bima
+COPY --from=builder /out/healthcheck /usr/local/bin/healthcheck
+COPY --from=builder /src/swaggers /app/swaggers
+%s
+USER 65532:65532
+
+EXPOSE %d %d
+
+HEALTHCHECK --interval=30s --timeout=3s --start-period=10s CMD ["/usr/local/bin/healthcheck", "http://127.0.0.1:%d/health"]
+
+ENTRYPOINT ["/app/bima", "run", "%s"]
+`
+
+const dockerignore = `.git
+.bima
+.vscode
+dist
+bima
+*.log
+*.oci.tar
+Dockerfile
+.dockerignore
+%s`
+
+const probe = `package main
+
+import (
+	"net/http"
+	"os"
+	"time"
+)
+
+func main() {
+	client := http.Client{Timeout: 3 * time.Second}
+	response, err := client.Get(os.Args[1])
+	if err != nil || response.StatusCode >= 400 {
+		os.Exit(1)
+	}
+}
+`
+
+const (
+	passwd = "root:x:0:0:root:/root:/sbin/nologin\nnonroot:x:65532:65532:nonroot:/home/nonroot:/sbin/nologin\n"
+	group  = "root:x:0:\nnonroot:x:65532:\n"
+)
+
+var certificates = []string{"/etc/ssl/certs/ca-certificates.crt", "/etc/pki/tls/certs/ca-bundle.crt", "/etc/ssl/cert.pem"}
+
+type blob struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int               `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+var invalidTag = regexp.MustCompile(`[^\w.-]`)
+
+func Dockerize(file string, force bool, withConfig bool) ([]string, error) {
+	env := configs.Env{}
+	if err := load(&env, file); err != nil {
+		return nil, err
+	}
+
+	wd, _ := os.Getwd()
+	source, err := filepath.Rel(wd, file)
+	if !filepath.IsAbs(file) {
+		source, err = filepath.Clean(file), nil
+	}
+
+	if err != nil || strings.HasPrefix(source, "..") {
+		return nil, fmt.Errorf("config %s must be inside project directory", file)
+	}
+
+	source = filepath.ToSlash(source)
+	copied, ignored := fmt.Sprintf("COPY --from=builder /src/%s /app/%s\n", source, filepath.Base(file)), ""
+	if !withConfig {
+		copied, ignored = "", source+"\n"
+	}
+
+	files := map[string]string{
+		"Dockerfile":    fmt.Sprintf(dockerfile, toolchainVersion(), strings.ReplaceAll(probe, "\n", `\n`), copied, env.HttpPort, env.RpcPort, env.HttpPort, filepath.Base(file)),
+		".dockerignore": fmt.Sprintf(dockerignore, ignored),
+	}
+
+	written := []string{}
+	for _, name := range []string{"Dockerfile", ".dockerignore"} {
+		if _, err := os.Stat(name); err == nil && !force {
+			return written, fmt.Errorf("%s already exists, use --force to overwrite", name)
+		}
+
+		if err := os.WriteFile(name, []byte(files[name]), 0644); err != nil {
+			return written, err
+		}
+
+		written = append(written, name)
+	}
+
+	return written, nil
+}
+
+func Image(name string, dir string, file string, arch string, stamp Stamp, withConfig bool) (string, error) {
+	env := configs.Env{}
+	if err := load(&env, file); err != nil {
+		return "", err
+	}
+
+	temp, err := os.MkdirTemp("", "bima-image")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(temp)
+
+	binary := fmt.Sprintf("%s/bima", temp)
+	bundle := Bundle{Release: true, Stamp: stamp}
+	target := Target{Os: "linux", Arch: arch}
+	err = hook("build", map[string]string{"BIMA_BUILD_TARGET": target.String(), "BIMA_BUILD_OUTPUT": binary}, func() error {
+		return bundle.compile(target, binary)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	healthcheck := fmt.Sprintf("%s/healthcheck", temp)
+	if err := os.WriteFile(fmt.Sprintf("%s/healthcheck.go", temp), []byte(probe), 0644); err != nil {
+		return "", err
+	}
+
+	err = command("cd %s && GOOS=linux GOARCH=%s CGO_ENABLED=0 go build -trimpath -ldflags '-s -w' -o healthcheck healthcheck.go").run(escape(temp), arch)
+	if err != nil {
+		return "", err
+	}
+
+	created, err := time.Parse(time.RFC3339, stamp.BuildTime)
+	if err != nil {
+		created = time.Now().UTC()
+	}
+
+	config := ""
+	if withConfig {
+		config = file
+	}
+
+	diff, layer, err := rootfs(binary, healthcheck, config, created)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := json.Marshal(map[string]interface{}{
+		"architecture": arch,
+		"os":           "linux",
+		"created":      created.Format(time.RFC3339),
+		"config": map[string]interface{}{
+			"User":       "65532:65532",
+			"WorkingDir": "/app",
+			"Env":        []string{"PATH=/usr/local/bin:/usr/bin:/bin", "SSL_CERT_FILE=/etc/ssl/certs/ca-certificates.crt", "ZONEINFO=/usr/share/zoneinfo.zip"},
+			"Entrypoint": []string{"/app/bima", "run", filepath.Base(file)},
+			"Healthcheck": map[string]interface{}{
+				"Test":        []string{"CMD", "/usr/local/bin/healthcheck", fmt.Sprintf("http://127.0.0.1:%d/health", env.HttpPort)},
+				"Interval":    30 * time.Second,
+				"Timeout":     3 * time.Second,
+				"StartPeriod": 10 * time.Second,
+				"Retries":     3,
+			},
+			"ExposedPorts": map[string]struct{}{fmt.Sprintf("%d/tcp", env.HttpPort): {}, fmt.Sprintf("%d/tcp", env.RpcPort): {}},
+			"Labels": map[string]string{
+				"org.opencontainers.image.version":  stamp.Version,
+				"org.opencontainers.image.revision": stamp.Commit,
+				"org.opencontainers.image.created":  created.Format(time.RFC3339),
+			},
+		},
+		"rootfs":  map[string]interface{}{"type": "layers", "diff_ids": []string{diff}},
+		"history": []map[string]string{{"created": created.Format(time.RFC3339), "created_by": "bima build --docker"}},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	blobs := map[string][]byte{}
+	image := blob{MediaType: "application/vnd.oci.image.config.v1+json", Digest: digest(content), Size: len(content)}
+	blobs[image.Digest] = content
+
+	layers := blob{MediaType: "application/vnd.oci.image.layer.v1.tar+gzip", Digest: digest(layer), Size: len(layer)}
+	blobs[layers.Digest] = layer
+
+	manifest, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"config":        image,
+		"layers":        []blob{layers},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	repository := strings.ToLower(filepath.Base(name))
+	tag := invalidTag.ReplaceAllString(stamp.Version, "-")
+	reference := blob{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest(manifest),
+		Size:      len(manifest),
+		Annotations: map[string]string{
+			"org.opencontainers.image.ref.name": tag,
+			"io.containerd.image.name":          fmt.Sprintf("%s:%s", repository, tag),
+		},
+	}
+	blobs[reference.Digest] = manifest
+
+	index, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.index.v1+json",
+		"manifests":     []blob{reference},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	path := fmt.Sprintf("%s/%s_%s.oci.tar", dir, repository, arch)
+	output, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer output.Close()
+
+	contents := map[string][]byte{"oci-layout": []byte(`{"imageLayoutVersion":"1.0.0"}`), "index.json": index}
+	names := []string{"oci-layout", "index.json"}
+	for _, d := range []string{image.Digest, layers.Digest, reference.Digest} {
+		name := fmt.Sprintf("blobs/sha256/%s", strings.TrimPrefix(d, "sha256:"))
+		contents[name] = blobs[d]
+		names = append(names, name)
+	}
+
+	archive := tar.NewWriter(output)
+	for _, name := range []string{"blobs/", "blobs/sha256/"} {
+		if err := archive.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: name, Mode: 0755, ModTime: created}); err != nil {
+			return "", err
+		}
+	}
+
+	for _, name := range names {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(contents[name])), ModTime: created}
+		if err := archive.WriteHeader(header); err != nil {
+			return "", err
+		}
+
+		if _, err := archive.Write(contents[name]); err != nil {
+			return "", err
+		}
+	}
+
+	return path, archive.Close()
+}
+
+func rootfs(binary string, healthcheck string, config string, created time.Time) (string, []byte, error) {
+	var layer bytes.Buffer
+	archive := tar.NewWriter(&layer)
+
+	directory := func(name string) error {
+		return archive.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: name + "/", Mode: 0755, ModTime: created})
+	}
+
+	write := func(name string, content []byte, mode int64) error {
+		header := &tar.Header{Name: name, Mode: mode, Size: int64(len(content)), ModTime: created}
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+
+		_, err := archive.Write(content)
+
+		return err
+	}
+
+	add := func(source string, name string, mode int64) error {
+		content, err := os.ReadFile(source)
+		if err != nil {
+			return err
+		}
+
+		return write(name, content, mode)
+	}
+
+	certificate := ""
+	for _, path := range certificates {
+		if _, err := os.Stat(path); err == nil {
+			certificate = path
+
+			break
+		}
+	}
+
+	if certificate == "" {
+		return "", nil, fmt.Errorf("ca certificates not found in %s", strings.Join(certificates, ", "))
+	}
+
+	zoneinfo, err := exec.Command("go", "env", "GOROOT").Output()
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, name := range []string{"etc", "etc/ssl", "etc/ssl/certs", "usr", "usr/local", "usr/local/bin", "usr/share", "root", "home", "app"} {
+		if err := directory(name); err != nil {
+			return "", nil, err
+		}
+	}
+
+	headers := []*tar.Header{
+		{Typeflag: tar.TypeDir, Name: "tmp/", Mode: 01777, ModTime: created},
+		{Typeflag: tar.TypeDir, Name: "home/nonroot/", Mode: 0700, Uid: 65532, Gid: 65532, ModTime: created},
+	}
+	for _, header := range headers {
+		if err := archive.WriteHeader(header); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if err := write("etc/passwd", []byte(passwd), 0644); err != nil {
+		return "", nil, err
+	}
+
+	if err := write("etc/group", []byte(group), 0644); err != nil {
+		return "", nil, err
+	}
+
+	if err := add(certificate, "etc/ssl/certs/ca-certificates.crt", 0644); err != nil {
+		return "", nil, err
+	}
+
+	if err := add(filepath.Join(strings.TrimSpace(string(zoneinfo)), "lib", "time", "zoneinfo.zip"), "usr/share/zoneinfo.zip", 0644); err != nil {
+		return "", nil, err
+	}
+
+	if err := add(healthcheck, "usr/local/bin/healthcheck", 0755); err != nil {
+		return "", nil, err
+	}
+
+	if err := add(binary, "app/bima", 0755); err != nil {
+		return "", nil, err
+	}
+
+	if config != "" {
+		if err := add(config, fmt.Sprintf("app/%s", filepath.Base(config)), 0644); err != nil {
+			return "", nil, err
+		}
+	}
+
+	err = filepath.WalkDir("swaggers", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name := fmt.Sprintf("app/%s", filepath.ToSlash(path))
+		if entry.IsDir() {
+			return directory(name)
+		}
+
+		return add(path, name, 0644)
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return "", nil, err
+	}
+
+	if err := archive.Close(); err != nil {
+		return "", nil, err
+	}
+
+	var compressed bytes.Buffer
+	zipper := gzip.NewWriter(&compressed)
+	if _, err := zipper.Write(layer.Bytes()); err != nil {
+		return "", nil, err
+	}
+
+	if err := zipper.Close(); err != nil {
+		return "", nil, err
+	}
+
+	return digest(layer.Bytes()), compressed.Bytes(), nil
+}
+
+func digest(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return fmt.Sprintf("sha256:%s", hex.EncodeToString(sum[:]))
+}
+
+func toolchainVersion() string {
+	mod, err := os.ReadFile("go.mod")
+	if err != nil {
+		return "1"
+	}
+
+	f, err := modfile.Parse("go.mod", mod, nil)
+	if err != nil || f.Go == nil {
+		return "1"
+	}
+
+	return f.Go.Version
+}
@@ -0,0 +1,145 @@
+package tool
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDockerize(t *testing.T) {
+	cases := []struct {
+		name    string
+		file    func(dir string) string
+		config  bool
+		copied  string
+		ignored string
+		fail    bool
+	}{
+		{name: "with config", file: func(string) string { return ".env" }, config: true, copied: "COPY --from=builder /src/.env /app/.env\n"},
+		{name: "nested config", file: func(string) string { return "configs/app.yaml" }, config: true, copied: "COPY --from=builder /src/configs/app.yaml /app/app.yaml\n"},
+		{name: "absolute config", file: func(dir string) string { return filepath.Join(dir, "configs", "app.yaml") }, config: true, copied: "COPY --from=builder /src/configs/app.yaml /app/app.yaml\n"},
+		{name: "without config", file: func(string) string { return ".env" }, ignored: ".env\n"},
+		{name: "outside project", file: func(string) string { return write(t, "outside.env", "APP_PORT=1\n") }, config: true, fail: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir, err := filepath.EvalSymlinks(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			chdir(t, dir)
+			if err := os.MkdirAll("configs", 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			_ = os.WriteFile(".env", []byte("APP_PORT=7001\nGRPC_PORT=7002\n"), 0644)
+			_ = os.WriteFile("configs/app.yaml", []byte("http_port: 7001\nrpc_port: 7002\n"), 0644)
+
+			file := c.file(dir)
+			written, err := Dockerize(file, false, c.config)
+			if c.fail {
+				if err == nil {
+					t.Fatalf("expect error, got %v", written)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dockerfile, _ := os.ReadFile("Dockerfile")
+			ignore, _ := os.ReadFile(".dockerignore")
+			if c.copied != "" && !strings.Contains(string(dockerfile), c.copied) {
+				t.Fatalf("expect Dockerfile to contain %q, got %s", c.copied, dockerfile)
+			}
+
+			if c.copied == "" && strings.Contains(string(dockerfile), "/app/"+filepath.Base(file)+"\n") {
+				t.Fatalf("expect Dockerfile not to copy config, got %s", dockerfile)
+			}
+
+			if !strings.HasSuffix(string(ignore), ".dockerignore\n"+c.ignored) {
+				t.Fatalf("expect .dockerignore to end with %q, got %s", c.ignored, ignore)
+			}
+
+			for _, expect := range []string{"COPY --from=builder /src/swaggers /app/swaggers", "EXPOSE 7001 7002", "http://127.0.0.1:7001/health", `"run", "` + filepath.Base(file) + `"`} {
+				if !strings.Contains(string(dockerfile), expect) {
+					t.Fatalf("expect Dockerfile to contain %s, got %s", expect, dockerfile)
+				}
+			}
+
+			if _, err := Dockerize(file, false, c.config); err == nil {
+				t.Fatal("expect existing Dockerfile to fail without force")
+			}
+		})
+	}
+}
+
+func TestRootfs(t *testing.T) {
+	binary := write(t, "bima", "binary")
+	healthcheck := write(t, "healthcheck", "probe")
+	config := write(t, ".env", "APP_PORT=7001\n")
+
+	cases := []struct {
+		name   string
+		config string
+		expect bool
+	}{
+		{name: "with config", config: config, expect: true},
+		{name: "without config"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			chdir(t, t.TempDir())
+
+			_, layer, err := rootfs(binary, healthcheck, c.config, time.Unix(0, 0))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			reader, err := gzip.NewReader(bytes.NewReader(layer))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			headers := map[string]*tar.Header{}
+			archive := tar.NewReader(reader)
+			for {
+				header, err := archive.Next()
+				if err == io.EOF {
+					break
+				}
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				headers[header.Name] = header
+			}
+
+			for _, name := range []string{"etc/passwd", "etc/group", "etc/ssl/certs/ca-certificates.crt", "usr/share/zoneinfo.zip", "usr/local/bin/healthcheck", "app/bima", "tmp/", "home/nonroot/"} {
+				if headers[name] == nil {
+					t.Fatalf("expect %s in layer", name)
+				}
+			}
+
+			if headers["tmp/"].Mode != 01777 || headers["home/nonroot/"].Uid != 65532 {
+				t.Fatalf("expect writable tmp and home owned by nonroot, got %o and %d", headers["tmp/"].Mode, headers["home/nonroot/"].Uid)
+			}
+
+			if _, ok := headers["app/.env"]; ok != c.expect {
+				t.Fatalf("expect config in layer to be %v", c.expect)
+			}
+		})
+	}
+}
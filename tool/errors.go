@@ -0,0 +1,81 @@
+package tool
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+
+	"github.com/fatih/color"
+	"mvdan.cc/sh/interp"
+)
+
+const (
+	ExitUsage = iota + 2
+	ExitEnvironment
+	ExitToolchain
+	ExitGeneration
+	ExitBuild
+)
+
+type Error struct {
+	Code int
+	Err  error
+}
+
+func Fail(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(interface{ ExitCode() int }); ok {
+		return err
+	}
+
+	return &Error{Code: code, Err: err}
+}
+
+func Failf(code int, format string, args ...interface{}) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+func Usage(usage string) error {
+	return &Error{Code: ExitUsage, Err: fmt.Errorf("Usage: %s", usage)}
+}
+
+func Code(err error) int {
+	var exit interface{ ExitCode() int }
+	if errors.As(err, &exit) && exit.ExitCode() != 0 {
+		return exit.ExitCode()
+	}
+
+	return 1
+}
+
+func Report(err error) {
+	if err == nil || Reason(err) == "" {
+		return
+	}
+
+	color.New(color.FgRed).Println(Reason(err))
+
+	var (
+		failure *Failure
+		status  interp.ExitStatus
+		exit    *exec.ExitError
+	)
+	if errors.As(err, &failure) && failure.Message != "" && failure.Err.Error() != "" && !errors.As(failure.Err, &status) && !errors.As(failure.Err, &exit) {
+		fmt.Println(failure.Err.Error())
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) ExitCode() int {
+	return e.Code
+}
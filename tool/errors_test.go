@@ -0,0 +1,103 @@
+package tool
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"mvdan.cc/sh/interp"
+)
+
+func TestCode(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		expect int
+	}{
+		{name: "plain", err: errors.New("failed"), expect: 1},
+		{name: "usage", err: Usage("bima run"), expect: ExitUsage},
+		{name: "fail", err: Fail(ExitEnvironment, errors.New("missing")), expect: ExitEnvironment},
+		{name: "failf", err: Failf(ExitToolchain, "protoc is not installed"), expect: ExitToolchain},
+		{name: "failure", err: NewFailure(ExitBuild, "Error building", errors.New("failed")), expect: ExitBuild},
+		{name: "wrapped", err: fmt.Errorf("run: %w", NewFailure(ExitGeneration, "Error", errors.New("failed"))), expect: ExitGeneration},
+		{name: "keep inner", err: Fail(ExitEnvironment, Failf(ExitToolchain, "failed")), expect: ExitToolchain},
+		{name: "shell status", err: &Failure{Err: interp.ExitStatus(7)}, expect: 7},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if code := Code(c.err); code != c.expect {
+				t.Fatalf("expect code %d got %d", c.expect, code)
+			}
+		})
+	}
+
+	if Fail(ExitBuild, nil) != nil || NewFailure(ExitBuild, "Error", nil) != nil {
+		t.Fatal("expect nil error to stay nil")
+	}
+}
+
+func TestReport(t *testing.T) {
+	_, exit := exec.Command("false").Output()
+	cases := []struct {
+		name   string
+		err    error
+		expect string
+	}{
+		{name: "plain", err: errors.New("failed"), expect: "failed\n"},
+		{name: "detail", err: NewFailure(ExitBuild, "Error building", errors.New("undefined: x")), expect: "Error building\nundefined: x\n"},
+		{name: "empty detail", err: NewFailure(ExitBuild, "Error building", errors.New("")), expect: "Error building\n"},
+		{name: "shell status", err: NewFailure(ExitBuild, "Error building", interp.ExitStatus(2)), expect: "Error building\n"},
+		{name: "exit error", err: NewFailure(ExitBuild, "Error building", exit), expect: "Error building\n"},
+		{name: "empty", err: errors.New(""), expect: ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if output, _ := record(t, func() { Report(c.err) }); output != c.expect {
+				t.Fatalf("expect %q got %q", c.expect, output)
+			}
+		})
+	}
+}
+
+func record(t *testing.T, fn func()) (string, string) {
+	t.Helper()
+
+	stdout, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stderr, stderrWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	originalStdout, originalStderr, output, disabled := os.Stdout, os.Stderr, color.Output, color.NoColor
+	os.Stdout, os.Stderr, color.Output, color.NoColor = stdoutWriter, stderrWriter, stdoutWriter, true
+	defer func() {
+		os.Stdout, os.Stderr, color.Output, color.NoColor = originalStdout, originalStderr, output, disabled
+	}()
+
+	outputs := make(chan string, 2)
+	for _, reader := range []*os.File{stdout, stderr} {
+		go func(reader *os.File) {
+			var content strings.Builder
+			_, _ = io.Copy(&content, reader)
+			outputs <- content.String()
+		}(reader)
+	}
+
+	fn()
+	stdoutWriter.Close()
+	stdoutContent := <-outputs
+	stderrWriter.Close()
+
+	return stdoutContent, <-outputs
+}
@@ -0,0 +1,109 @@
+package tool
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bimalabs/framework/v4/configs"
+	"github.com/bimalabs/framework/v4/routes"
+	"github.com/fatih/color"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type tail struct {
+	mutex   sync.Mutex
+	size    int
+	lines   []string
+	partial []byte
+}
+
+func (t *tail) Write(p []byte) (int, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.partial = append(t.partial, p...)
+	for {
+		i := bytes.IndexByte(t.partial, '\n')
+		if i == -1 {
+			break
+		}
+
+		t.lines = append(t.lines, string(t.partial[:i]))
+		t.partial = t.partial[i+1:]
+	}
+
+	if len(t.lines) > t.size {
+		t.lines = t.lines[len(t.lines)-t.size:]
+	}
+
+	return len(p), nil
+}
+
+func (t *tail) String() string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	lines := t.lines
+	if len(t.partial) > 0 {
+		lines = append(lines, string(t.partial))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func report(env configs.Env, logs *tail, timeout time.Duration, done <-chan struct{}) {
+	deadline := time.After(timeout)
+	for {
+		if listening(env.HttpPort) && serving(env.RpcPort) {
+			util := color.New(color.FgGreen, color.Bold)
+			util.Println("Application is ready")
+			fmt.Printf("HTTP: http://localhost:%d%s\n", env.HttpPort, env.ApiPrefix)
+			fmt.Printf("gRPC: localhost:%d\n", env.RpcPort)
+			fmt.Printf("Health: http://localhost:%d%s\n", env.HttpPort, routes.HelthPath)
+			fmt.Printf("Swagger: http://localhost:%d%s%s\n", env.HttpPort, env.ApiPrefix, routes.ApiDocPath)
+
+			return
+		}
+
+		select {
+		case <-done:
+			return
+		case <-deadline:
+			color.New(color.FgRed).Printf("Application is not ready after %s, last output:\n", timeout)
+			fmt.Println(logs.String())
+
+			return
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+func serving(port int) bool {
+	if !listening(port) {
+		return false
+	}
+
+	conn, err := grpc.NewClient(fmt.Sprintf("127.0.0.1:%d", port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	response, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		return status.Code(err) == codes.Unimplemented
+	}
+
+	return response.GetStatus() == grpc_health_v1.HealthCheckResponse_SERVING
+}
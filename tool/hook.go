@@ -0,0 +1,50 @@
+package tool
+
+import (
+	"fmt"
+	"os"
+)
+
+var steps = map[string]bool{"dump": true, "genproto": true, "build": true, "module-add": true}
+
+type Hook struct {
+	Pre  []string `yaml:"pre"`
+	Post []string `yaml:"post"`
+}
+
+func hook(step string, env map[string]string, action func() error) error {
+	project, err := LoadProject()
+	if err != nil {
+		return err
+	}
+
+	h, ok := project.Hooks[step]
+	if !ok {
+		return action()
+	}
+
+	context := map[string]string{"BIMA_STEP": step}
+	if wd, err := os.Getwd(); err == nil {
+		context["BIMA_PROJECT_DIR"] = wd
+	}
+
+	for k, v := range env {
+		context[k] = v
+	}
+
+	context["BIMA_HOOK"] = "pre"
+	if err := (Chore{Cmds: h.Pre, Env: context}).run(nil); err != nil {
+		return fmt.Errorf("pre %s hook failed: %s", step, err.Error())
+	}
+
+	if err := action(); err != nil {
+		return err
+	}
+
+	context["BIMA_HOOK"] = "post"
+	if err := (Chore{Cmds: h.Post, Env: context}).run(nil); err != nil {
+		return fmt.Errorf("post %s hook failed: %s", step, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package tool
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestHook(t *testing.T) {
+	chdir(t, t.TempDir())
+
+	record := func(line string) error {
+		file, err := os.OpenFile("trace", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = file.WriteString(line + "\n")
+
+		return err
+	}
+
+	cases := []struct {
+		name   string
+		config string
+		action error
+		err    string
+		expect string
+	}{
+		{
+			name:   "no hooks",
+			config: "",
+			expect: "action\n",
+		},
+		{
+			name:   "pre and post",
+			config: "hooks:\n  build:\n    pre: ['echo \"$BIMA_HOOK $BIMA_STEP $BIMA_BUILD_OUTPUT\" >> trace']\n    post: ['echo \"$BIMA_HOOK $BIMA_STEP\" >> trace']\n",
+			expect: "pre build bin/app\naction\npost build\n",
+		},
+		{
+			name:   "other step",
+			config: "hooks:\n  dump:\n    pre: ['echo pre >> trace']\n",
+			expect: "action\n",
+		},
+		{
+			name:   "pre failed",
+			config: "hooks:\n  build:\n    pre: ['exit 3']\n    post: ['echo post >> trace']\n",
+			err:    "pre build hook failed: exit status 3",
+			expect: "",
+		},
+		{
+			name:   "action failed",
+			config: "hooks:\n  build:\n    pre: ['echo pre >> trace']\n    post: ['echo post >> trace']\n",
+			action: errors.New("compile error"),
+			err:    "compile error",
+			expect: "pre\naction\n",
+		},
+		{
+			name:   "post failed",
+			config: "hooks:\n  build:\n    post: ['exit 1']\n",
+			err:    "post build hook failed: exit status 1",
+			expect: "action\n",
+		},
+		{
+			name:   "unknown step",
+			config: "hooks:\n  deploy:\n    pre: ['echo pre >> trace']\n",
+			err:    "invalid bima.yaml: unknown hook deploy",
+			expect: "",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_ = os.Remove("trace")
+			_ = os.Remove(project)
+			if c.config != "" {
+				if err := os.WriteFile(project, []byte(c.config), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := hook("build", map[string]string{"BIMA_BUILD_OUTPUT": "bin/app"}, func() error {
+				if err := record("action"); err != nil {
+					return err
+				}
+
+				return c.action
+			})
+			if (err == nil && c.err != "") || (err != nil && err.Error() != c.err) {
+				t.Fatalf("expect error %q got %v", c.err, err)
+			}
+
+			content, _ := os.ReadFile("trace")
+			if string(content) != c.expect {
+				t.Fatalf("expect trace %q got %q", c.expect, content)
+			}
+		})
+	}
+}
+
+func TestTaskHook(t *testing.T) {
+	chdir(t, t.TempDir())
+
+	config := "hooks:\n  dump:\n    pre: ['echo \"$BIMA_STEP $BIMA_TARGET\" > trace']\n    post: ['exit 2']\n"
+	if err := os.WriteFile(project, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	task := Task{
+		Name:    "dump",
+		Message: "Error dumping service container",
+		Action:  func() error { return nil },
+		hook:    "dump",
+		env:     map[string]string{"BIMA_TARGET": "linux/amd64"},
+		code:    ExitGeneration,
+	}
+
+	err := NewPipeline(false).Run(task)
+	if Code(err) != ExitGeneration || Reason(err) != "Error dumping service container" {
+		t.Fatalf("expect generation failure got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "post dump hook failed") {
+		t.Fatalf("expect post hook failure got %v", err)
+	}
+
+	content, _ := os.ReadFile("trace")
+	if string(content) != "dump linux/amd64\n" {
+		t.Fatalf("expect pre hook environment got %q", content)
+	}
+}
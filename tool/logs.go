@@ -0,0 +1,301 @@
+package tool
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bimalabs/cli/bima"
+	"github.com/fatih/color"
+	"github.com/sirupsen/logrus"
+)
+
+const logging = ".bima/logs"
+
+type (
+	rotator struct {
+		mutex   sync.Mutex
+		path    string
+		file    *os.File
+		written int64
+	}
+
+	printer struct {
+		mutex   sync.Mutex
+		output  io.Writer
+		pretty  bool
+		level   logrus.Level
+		partial []byte
+	}
+)
+
+var textLevel = regexp.MustCompile(`level=(\w+)`)
+
+func Logs(profile Profile, follow bool, lines int) error {
+	file, err := os.Open(profile.LogFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no log file found for %s", profile.name())
+		}
+
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	console := Console()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+
+	all := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if lines > 0 && len(all) > lines {
+		all = all[len(all)-lines:]
+	}
+
+	for _, line := range all {
+		_, _ = console.Write([]byte(line + "\n"))
+	}
+
+	if !follow {
+		return nil
+	}
+
+	for {
+		time.Sleep(500 * time.Millisecond)
+
+		file, err = reopen(profile.LogFile(), file, console)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func reopen(path string, file *os.File, output io.Writer) (*os.File, error) {
+	if _, err := io.Copy(output, file); err != nil {
+		return file, err
+	}
+
+	current, err := os.Stat(path)
+	if err != nil {
+		return file, nil
+	}
+
+	opened, err := file.Stat()
+	if err != nil {
+		return file, err
+	}
+
+	if os.SameFile(current, opened) {
+		offset, err := file.Seek(0, io.SeekCurrent)
+		if err != nil || current.Size() >= offset {
+			return file, err
+		}
+
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return file, err
+		}
+
+		_, err = io.Copy(output, file)
+
+		return file, err
+	}
+
+	if _, err := io.Copy(output, file); err != nil {
+		return file, err
+	}
+
+	next, err := os.Open(path)
+	if err != nil {
+		return file, nil
+	}
+
+	_ = file.Close()
+	_, err = io.Copy(output, next)
+
+	return next, err
+}
+
+func Console() io.Writer {
+	level, err := logrus.ParseLevel(bima.LogLevel)
+	if err != nil {
+		level = logrus.TraceLevel
+	}
+
+	return &printer{output: os.Stdout, pretty: bima.PrettyLog, level: level}
+}
+
+func capture(profile Profile, logs *tail) (io.Writer, io.Closer, error) {
+	file := &rotator{path: profile.LogFile()}
+	if err := file.open(); err != nil {
+		return nil, nil, err
+	}
+
+	return io.MultiWriter(Console(), file, logs), file, nil
+}
+
+func (p Profile) name() string {
+	if p == "" {
+		return "app"
+	}
+
+	return string(p)
+}
+
+func (r *rotator) open() error {
+	if err := os.MkdirAll(logging, 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+
+		return err
+	}
+
+	r.file = file
+	r.written = info.Size()
+
+	return nil
+}
+
+func (r *rotator) rotate() error {
+	_ = r.file.Close()
+
+	for i := bima.LogBackups - 1; i > 0; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+
+	if bima.LogBackups > 0 {
+		_ = os.Rename(r.path, fmt.Sprintf("%s.1", r.path))
+	} else {
+		_ = os.Remove(r.path)
+	}
+
+	return r.open()
+}
+
+func (r *rotator) Write(p []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.written+int64(len(p)) > bima.LogMaxSize && r.written > 0 {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.written += int64(n)
+
+	return n, err
+}
+
+func (r *rotator) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.file.Close()
+}
+
+func (p *printer) Write(b []byte) (int, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.partial = append(p.partial, b...)
+	for {
+		i := bytes.IndexByte(p.partial, '\n')
+		if i == -1 {
+			break
+		}
+
+		p.print(p.partial[:i])
+		p.partial = p.partial[i+1:]
+	}
+
+	return len(b), nil
+}
+
+func (p *printer) print(line []byte) {
+	entry := map[string]interface{}{}
+	if err := json.Unmarshal(line, &entry); err != nil || entry["level"] == nil {
+		if match := textLevel.FindSubmatch(line); match != nil {
+			if level, err := logrus.ParseLevel(string(match[1])); err == nil && level > p.level {
+				return
+			}
+		}
+
+		fmt.Fprintf(p.output, "%s\n", line)
+
+		return
+	}
+
+	level, err := logrus.ParseLevel(fmt.Sprint(entry["level"]))
+	if err == nil && level > p.level {
+		return
+	}
+
+	if !p.pretty {
+		fmt.Fprintf(p.output, "%s\n", line)
+
+		return
+	}
+
+	var message strings.Builder
+	if t, ok := entry["time"].(string); ok {
+		if parsed, err := time.Parse(time.RFC3339, t); err == nil {
+			t = parsed.Format("15:04:05")
+		}
+
+		message.WriteString(t)
+		message.WriteString(" ")
+	}
+
+	message.WriteString(paint(level).Sprintf("%-7s", strings.ToUpper(level.String())))
+	message.WriteString(" ")
+	message.WriteString(fmt.Sprint(entry["msg"]))
+
+	keys := make([]string, 0, len(entry))
+	for k := range entry {
+		if k != "level" && k != "msg" && k != "time" {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+	for _, k := range keys {
+		message.WriteString(" ")
+		message.WriteString(color.New(color.Faint).Sprintf("%s=", k))
+		message.WriteString(fmt.Sprint(entry[k]))
+	}
+
+	fmt.Fprintln(p.output, message.String())
+}
+
+func paint(level logrus.Level) *color.Color {
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel:
+		return color.New(color.FgRed, color.Bold)
+	case logrus.WarnLevel:
+		return color.New(color.FgYellow)
+	case logrus.InfoLevel:
+		return color.New(color.FgCyan)
+	default:
+		return color.New(color.FgWhite)
+	}
+}
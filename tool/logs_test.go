@@ -0,0 +1,105 @@
+package tool
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/bimalabs/cli/bima"
+)
+
+func TestReopen(t *testing.T) {
+	chdir(t, t.TempDir())
+
+	size, backups := bima.LogMaxSize, bima.LogBackups
+	bima.LogMaxSize, bima.LogBackups = 16, 2
+	t.Cleanup(func() {
+		bima.LogMaxSize, bima.LogBackups = size, backups
+	})
+
+	path := Profile("").LogFile()
+	writer := &rotator{path: path}
+	if err := writer.open(); err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+
+	write := func(line string) {
+		if _, err := writer.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("first line\n")
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var output bytes.Buffer
+	follow := func(expect string) {
+		t.Helper()
+
+		output.Reset()
+		if file, err = reopen(path, file, &output); err != nil {
+			t.Fatal(err)
+		}
+
+		if output.String() != expect {
+			t.Fatalf("expect %q got %q", expect, output.String())
+		}
+	}
+
+	follow("first line\n")
+	follow("")
+
+	write("rotated 1\n")
+	follow("rotated 1\n")
+
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Fatalf("expect backup file: %v", err)
+	}
+
+	write("long line 2\n")
+	follow("long line 2\n")
+
+	write("tail 3\n")
+	follow("tail 3\n")
+	follow("")
+
+	if _, err := os.Stat(path + ".2"); err != nil {
+		t.Fatalf("expect second backup file: %v", err)
+	}
+
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	follow("new\n")
+
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+
+	old, err := os.OpenFile(path+".1", os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = old.WriteString("late\n")
+	_ = old.Close()
+
+	if err := os.WriteFile(path, []byte("fresh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	follow("late\nfresh\n")
+}
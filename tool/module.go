@@ -2,12 +2,10 @@ package tool
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
 	"net/url"
 	"os"
-	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -38,75 +36,67 @@ type (
 )
 
 func (m Module) Create(file string) error {
-	if err := Call("dump"); err != nil {
-		color.New(color.FgRed).Println("Error updating services container")
+	return hook("module-add", map[string]string{"BIMA_MODULE": string(m), "BIMA_CONFIG": file}, func() error {
+		return m.generate(file)
+	})
+}
 
+func (m Module) generate(file string) error {
+	if err := NewPipeline(false).Run(Dump()); err != nil {
 		return err
 	}
 
 	env := configs.Env{}
-	config(&env, file, filepath.Ext(file))
+	if err := config(&env, file); err != nil {
+		return err
+	}
 
 	generator := NewGenerator(env.Db.Driver, env.ApiPrefix)
 
 	termColor := color.New(color.FgGreen, color.Bold)
 	err := create(generator, termColor, string(m))
 	if err != nil {
-		color.New(color.FgRed).Println(err.Error())
-		_ = m.Remove()
-
-		return err
-	}
-
-	if err = Call("genproto"); err != nil {
-		color.New(color.FgRed).Println("Error generate codes from proto files")
-		_ = m.Remove()
-
 		return err
 	}
 
-	if err = Call("clean"); err != nil {
-		color.New(color.FgRed).Println("Error cleaning dependencies")
+	if err = NewPipeline(false).Run(Genproto(), Clean(), Dump(), Tidy()); err != nil {
 		_ = m.Remove()
 
 		return err
 	}
 
-	if err = Call("dump"); err != nil {
-		color.New(color.FgRed).Println("Error updating services container")
-		_ = m.Remove()
+	return nil
+}
 
+func (m Module) Remove() error {
+	if err := remove(string(m)); err != nil {
 		return err
 	}
 
-	if err = Call("clean"); err != nil {
-		color.New(color.FgRed).Println("Error cleaning dependencies")
-		_ = m.Remove()
-
+	if err := NewPipeline(false).Run(Dump(), Clean()); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (m Module) Remove() error {
-	remove(string(m))
-	if err := Call("dump"); err != nil {
-		color.New(color.FgRed).Println("Error updating services container")
-
-		return err
+func Modules() []string {
+	modules := []string{}
+	wd, _ := os.Getwd()
+	if _, err := os.Stat(fmt.Sprintf("%s/%s", wd, c)); err != nil {
+		return modules
 	}
 
-	if err := Call("clean"); err != nil {
-		color.New(color.FgRed).Println("Error cleaning dependencies")
-
-		return err
+	for _, v := range parseModule(wd) {
+		if name, ok := strings.CutPrefix(v, "module:"); ok {
+			modules = append(modules, name)
+		}
 	}
 
-	return nil
+	return modules
 }
 
-func remove(module string) {
+func remove(module string) error {
 	util := color.New(color.FgGreen, color.Bold)
 	workDir, _ := os.Getwd()
 	pluralizer := pluralize.NewClient()
@@ -124,13 +114,12 @@ func remove(module string) {
 	}
 
 	if !exist {
-		util.Println("Module is not registered")
-		return
+		return Failf(ExitUsage, "module %s is not registered", module)
 	}
 
 	mod, err := os.ReadFile(fmt.Sprintf("%s/go.mod", workDir))
 	if err != nil {
-		panic(err)
+		return Fail(ExitEnvironment, err)
 	}
 
 	jsonModules := fmt.Sprintf("%s/swaggers/modules.json", workDir)
@@ -182,6 +171,8 @@ func remove(module string) {
 	fmt.Print("Module ")
 	util.Print(module)
 	util.Println(" deleted")
+
+	return nil
 }
 
 func parseModule(dir string) []string {
@@ -221,13 +212,13 @@ func create(factory *generators.Factory, util *color.Color, name string) error {
 	for more {
 		err := interact.NewInteraction("Add new column?").Resolve(&more)
 		if err != nil {
-			color.New(color.FgRed).Println(err.Error())
-
-			return err
+			return Fail(ExitUsage, err)
 		}
 
 		if more {
-			column(util, &field, mapType)
+			if err := column(util, &field, mapType); err != nil {
+				return Fail(ExitUsage, err)
+			}
 
 			field.Name = strings.Replace(field.Name, " ", "", -1)
 			column := generators.FieldTemplate{}
@@ -247,7 +238,7 @@ func create(factory *generators.Factory, util *color.Color, name string) error {
 	}
 
 	if len(module.Fields) < 1 {
-		return errors.New("you must have at least one column in table")
+		return Failf(ExitUsage, "you must have at least one column in table")
 	}
 
 	factory.Generate(module)
@@ -260,20 +251,19 @@ func create(factory *generators.Factory, util *color.Color, name string) error {
 	return nil
 }
 
-func column(util *color.Color, field *generators.FieldTemplate, mapType utils.Type) {
-	err := interact.NewInteraction("Input column name?").Resolve(&field.Name)
-	if err != nil {
-		util.Println(err.Error())
-		column(util, field, mapType)
-	}
+func column(util *color.Color, field *generators.FieldTemplate, mapType utils.Type) error {
+	for field.Name == "" {
+		if err := interact.NewInteraction("Input column name?").Resolve(&field.Name); err != nil {
+			return err
+		}
 
-	if field.Name == "" {
-		util.Println("Column name is required")
-		column(util, field, mapType)
+		if field.Name == "" {
+			util.Println("Column name is required")
+		}
 	}
 
 	field.ProtobufType = "string"
-	err = interact.NewInteraction("Input data type?",
+	err := interact.NewInteraction("Input data type?",
 		interact.Choice{Display: "string", Value: "string"},
 		interact.Choice{Display: "bool", Value: "bool"},
 		interact.Choice{Display: "int32", Value: "int32"},
@@ -290,15 +280,11 @@ func column(util *color.Color, field *generators.FieldTemplate, mapType utils.Ty
 		interact.Choice{Display: "sfixed64", Value: "sfixed64"},
 	).Resolve(&field.ProtobufType)
 	if err != nil {
-		util.Println(err.Error())
-		column(util, field, mapType)
+		return err
 	}
 
 	field.GolangType = mapType.Value(field.ProtobufType)
 	field.IsRequired = true
-	err = interact.NewInteraction("Is column required?").Resolve(&field.IsRequired)
-	if err != nil {
-		util.Println(err.Error())
-		column(util, field, mapType)
-	}
+
+	return interact.NewInteraction("Is column required?").Resolve(&field.IsRequired)
 }
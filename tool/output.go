@@ -0,0 +1,146 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/bimalabs/cli/bima"
+	"github.com/briandowns/spinner"
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"mvdan.cc/sh/interp"
+)
+
+type (
+	Progress interface {
+		Start()
+		Stop()
+	}
+
+	line   string
+	silent struct{}
+)
+
+var (
+	result  io.Writer = os.Stdout
+	emitted bool
+)
+
+func Json() bool {
+	return bima.Output == "json"
+}
+
+func Redirect() {
+	result = os.Stdout
+	os.Stdout = os.Stderr
+	color.Output = os.Stderr
+}
+
+func Emit(v interface{}) error {
+	if !Json() {
+		return nil
+	}
+
+	emitted = true
+	encoder := json.NewEncoder(result)
+	encoder.SetIndent("", "    ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(v)
+}
+
+func Emitted() bool {
+	return emitted
+}
+
+func Spinner(suffix string) Progress {
+	if Json() || bima.Quiet {
+		return silent{}
+	}
+
+	if bima.Verbose || !Terminal() {
+		return line(strings.TrimSpace(suffix))
+	}
+
+	progress := spinner.New(spinner.CharSets[bima.SpinerIndex], bima.Duration)
+	progress.Suffix = suffix
+
+	return progress
+}
+
+func Terminal() bool {
+	fd := os.Stdout.Fd()
+
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+func (l line) Start() {
+	fmt.Println(string(l))
+}
+
+func (line) Stop() {}
+
+func (silent) Start() {}
+
+func (silent) Stop() {}
+
+func trace(name string, message string) {
+	if !bima.Verbose {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "%s %s\n", color.New(color.Bold).Sprintf("[%s]", name), message)
+}
+
+func execute(ctx context.Context, path string, args []string) error {
+	return measure(strings.Join(args, " "), func() error {
+		return interp.DefaultExec(ctx, path, args)
+	})
+}
+
+func call(cmd *exec.Cmd) ([]byte, error) {
+	var output []byte
+	err := measure(strings.Join(cmd.Args, " "), func() error {
+		var err error
+		output, err = cmd.CombinedOutput()
+
+		return err
+	})
+
+	return output, err
+}
+
+func measure(cmd string, fn func() error) error {
+	if !bima.Verbose {
+		return fn()
+	}
+
+	trace("exec", cmd)
+
+	start := time.Now()
+	err := fn()
+
+	code := 0
+	var exit *exec.ExitError
+	switch status := err.(type) {
+	case nil:
+	case interp.ExitStatus:
+		code = int(status)
+	default:
+		code = -1
+		if errors.As(err, &exit) {
+			code = exit.ExitCode()
+		}
+	}
+
+	trace("exec", fmt.Sprintf("%s exited %d in %s", cmd, code, time.Since(start).Round(time.Millisecond)))
+
+	return err
+}
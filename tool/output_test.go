@@ -0,0 +1,93 @@
+package tool
+
+import (
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bimalabs/cli/bima"
+)
+
+func TestVerbose(t *testing.T) {
+	verbose := bima.Verbose
+	bima.Verbose = true
+	t.Cleanup(func() {
+		bima.Verbose = verbose
+	})
+
+	cases := []struct {
+		name   string
+		run    func() error
+		expect string
+	}{
+		{
+			name: "exec",
+			run: func() error {
+				_, err := call(exec.Command("sh", "-c", "exit 3"))
+
+				return err
+			},
+			expect: `(?m)^\[exec\] sh -c exit 3\n\[exec\] sh -c exit 3 exited 3 in \S+\n$`,
+		},
+		{
+			name: "missing",
+			run: func() error {
+				_, err := call(exec.Command("bima-missing-binary"))
+
+				return err
+			},
+			expect: `(?m)^\[exec\] bima-missing-binary\n\[exec\] bima-missing-binary exited -1 in \S+\n$`,
+		},
+		{
+			name: "shell",
+			run: func() error {
+				return command("sh -c %s").run(escape("exit 4"))
+			},
+			expect: `(?m)^\[exec\] sh -c exit 4\n\[exec\] sh -c exit 4 exited 4 in \S+\n$`,
+		},
+		{
+			name: "success",
+			run: func() error {
+				return command("sh -c true").run()
+			},
+			expect: `(?m)^\[exec\] sh -c true\n\[exec\] sh -c true exited 0 in \S+\n$`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, stderr := record(t, func() { _ = c.run() })
+			if !regexp.MustCompile(c.expect).MatchString(stderr) {
+				t.Fatalf("expect trace matching %q got %q", c.expect, stderr)
+			}
+		})
+	}
+
+	if _, ok := Spinner(" Building... ").(line); !ok {
+		t.Fatal("expect verbose mode to print progress as plain line")
+	}
+}
+
+func TestQuiet(t *testing.T) {
+	quiet := bima.Quiet
+	bima.Quiet = true
+	t.Cleanup(func() {
+		bima.Quiet = quiet
+	})
+
+	stdout, stderr := record(t, func() {
+		Spinner(" Building... ").Start()
+		_ = command("echo %s").run("done")
+	})
+	if stdout != "" || stderr != "" {
+		t.Fatalf("expect no output got %q and %q", stdout, stderr)
+	}
+
+	stdout, stderr = record(t, func() {
+		_ = command("sh -c %s").run(escape("echo failed; exit 1"))
+	})
+	if stdout != "" || strings.TrimSpace(stderr) != "failed" {
+		t.Fatalf("expect failure output on stderr only got %q and %q", stdout, stderr)
+	}
+}
@@ -0,0 +1,132 @@
+package tool
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/bimalabs/cli/bima"
+	"golang.org/x/mod/modfile"
+)
+
+const plugins = ".bima/plugins"
+
+type Plugin struct {
+	Name string
+	Path string
+}
+
+func Lookup(name string) (Plugin, bool) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return Plugin{}, false
+	}
+
+	for _, candidate := range []string{filepath.Join(pluginDir(), "bima-"+name), "bima-" + name} {
+		path, err := exec.LookPath(candidate)
+		if err != nil {
+			continue
+		}
+
+		if path, err = filepath.Abs(path); err == nil {
+			return Plugin{Name: name, Path: path}, true
+		}
+	}
+
+	return Plugin{}, false
+}
+
+func Plugins() []Plugin {
+	found := map[string]Plugin{}
+	dirs := append([]string{pluginDir()}, filepath.SplitList(os.Getenv("PATH"))...)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), "bima-")
+			if !ok || entry.IsDir() {
+				continue
+			}
+
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+
+			if _, exist := found[name]; exist || name == "" {
+				continue
+			}
+
+			info, err := entry.Info()
+			if err != nil || (runtime.GOOS != "windows" && info.Mode()&0111 == 0) {
+				continue
+			}
+
+			path, err := filepath.Abs(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				continue
+			}
+
+			found[name] = Plugin{Name: name, Path: path}
+		}
+	}
+
+	list := make([]Plugin, 0, len(found))
+	for _, p := range found {
+		list = append(list, p)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list
+}
+
+func pluginDir() string {
+	if root, err := Root(""); err == nil {
+		return filepath.Join(root, plugins)
+	}
+
+	return plugins
+}
+
+func (p Plugin) Exec(args []string) error {
+	cmd := exec.Command(p.Path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = result
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), fmt.Sprintf("BIMA_OUTPUT=%s", bima.Output))
+	emitted = true
+	for k, v := range pluginEnv() {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	return cmd.Run()
+}
+
+func pluginEnv() map[string]string {
+	wd, _ := os.Getwd()
+	env := map[string]string{
+		"BIMA_PROJECT_DIR":       wd,
+		"BIMA_CLI_VERSION":       bima.Version,
+		"BIMA_FRAMEWORK_VERSION": Framework(),
+		"BIMA_MODULE_PATH":       "",
+		"BIMA_CONFIG":            "",
+	}
+
+	if mod, err := os.ReadFile("go.mod"); err == nil {
+		env["BIMA_MODULE_PATH"] = modfile.ModulePath(mod)
+	}
+
+	if _, err := os.Stat(".env"); err == nil {
+		env["BIMA_CONFIG"] = filepath.Join(wd, ".env")
+	}
+
+	return env
+}
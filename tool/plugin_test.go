@@ -0,0 +1,61 @@
+package tool
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	chdir(t, t.TempDir())
+
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+
+	files := map[string]os.FileMode{
+		filepath.Join(bin, "bima-remote"):      0755,
+		filepath.Join(bin, "bima-shared"):      0755,
+		filepath.Join(bin, "bima-plain"):       0644,
+		filepath.Join(plugins, "bima-local"):   0755,
+		filepath.Join(plugins, "bima-shared"):  0755,
+		filepath.Join(plugins, "bima-private"): 0600,
+	}
+
+	if err := os.MkdirAll(plugins, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, mode := range files {
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wd, _ := os.Getwd()
+	cases := []struct {
+		name   string
+		expect string
+	}{
+		{name: "remote", expect: filepath.Join(bin, "bima-remote")},
+		{name: "local", expect: filepath.Join(wd, plugins, "bima-local")},
+		{name: "shared", expect: filepath.Join(wd, plugins, "bima-shared")},
+		{name: "plain"},
+		{name: "private"},
+		{name: "missing"},
+		{name: ""},
+		{name: "../bima-local"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			plugin, ok := Lookup(c.name)
+			if ok != (c.expect != "") {
+				t.Fatalf("expect found to be %v, got %+v", c.expect != "", plugin)
+			}
+
+			if plugin.Path != c.expect {
+				t.Fatalf("expect %s, got %s", c.expect, plugin.Path)
+			}
+		})
+	}
+}
@@ -0,0 +1,372 @@
+package tool
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bimalabs/cli/bima"
+	"github.com/bimalabs/framework/v4/configs"
+	"gopkg.in/yaml.v2"
+)
+
+const profiles = ".bima/profiles"
+
+var profileName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+type (
+	Profile string
+
+	state struct {
+		Config   string `json:"config"`
+		HttpPort int    `json:"http_port"`
+		RpcPort  int    `json:"rpc_port"`
+	}
+)
+
+func (p Profile) Validate() error {
+	if p == "" {
+		return nil
+	}
+
+	if !profileName.MatchString(string(p)) {
+		return Failf(ExitUsage, "invalid profile name %s, only letters, digits, _ and - are allowed", p)
+	}
+
+	if string(p) == Profile("").name() {
+		return Failf(ExitUsage, "profile name %s is reserved for default profile", p)
+	}
+
+	return nil
+}
+
+func (p Profile) PidFile() string {
+	if p == "" {
+		return ".pid"
+	}
+
+	return fmt.Sprintf("%s/%s/pid", profiles, p)
+}
+
+func (p Profile) LogFile() string {
+	return fmt.Sprintf("%s/%s.log", logging, p.name())
+}
+
+func (p Profile) Pid() int {
+	content, err := os.ReadFile(p.PidFile())
+	if err != nil {
+		return 0
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return 0
+	}
+
+	return pid
+}
+
+func (p Profile) Running() bool {
+	pid := p.Pid()
+
+	return pid != 0 && p.owned(pid)
+}
+
+func (p Profile) Stop(grace time.Duration) error {
+	pid := p.Pid()
+	if pid == 0 {
+		return nil
+	}
+
+	defer os.Remove(p.PidFile())
+
+	if !p.owned(pid) {
+		return nil
+	}
+
+	return terminate(pid, grace)
+}
+
+func (p Profile) Run(file string, httpPort int, rpcPort int) error {
+	env, cmd, err := p.prepare(file, httpPort, rpcPort)
+	if err != nil {
+		return err
+	}
+
+	logs := &tail{size: 20}
+	stdout, log, err := capture(p, logs)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+
+	cmd.Stdout = stdout
+	cmd.Stderr = stdout
+	if err := p.start(cmd); err != nil {
+		return err
+	}
+	defer os.Remove(p.PidFile())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go report(env, logs, bima.StartupTimeout, done)
+	go func() {
+		select {
+		case <-signals:
+			_ = terminate(cmd.Process.Pid, bima.ShutdownTimeout)
+		case <-done:
+		}
+	}()
+
+	return cmd.Wait()
+}
+
+func (p Profile) Detach(files []string, overrides []string, httpPort int, rpcPort int) (int, error) {
+	if err := p.Stop(bima.ShutdownTimeout); err != nil {
+		return 0, err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return 0, err
+	}
+
+	args := []string{"--quiet", "--project", wd, "run", "--foreground", "-p", string(p), "-t", bima.ShutdownTimeout.String(), "-w", bima.StartupTimeout.String(), "-l", bima.LogLevel}
+	for _, file := range files {
+		args = append(args, "-c", file)
+	}
+
+	for _, v := range overrides {
+		args = append(args, "--set", v)
+	}
+
+	if httpPort != 0 {
+		args = append(args, "--http-port", strconv.Itoa(httpPort))
+	}
+
+	if rpcPort != 0 {
+		args = append(args, "--grpc-port", strconv.Itoa(rpcPort))
+	}
+
+	cmd := exec.Command(executable, args...)
+	cmd.Dir = wd
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	deadline := time.After(bima.StartupTimeout)
+	for {
+		if pid := p.Pid(); pid != 0 {
+			return pid, nil
+		}
+
+		select {
+		case <-exited:
+			return 0, fmt.Errorf("application is not started, see %s", p.LogFile())
+		case <-deadline:
+			return 0, fmt.Errorf("application is not started after %s, see %s", bima.StartupTimeout, p.LogFile())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func (p Profile) prepare(file string, httpPort int, rpcPort int) (configs.Env, *exec.Cmd, error) {
+	env := configs.Env{}
+	if p.Running() {
+		if err := p.Stop(bima.ShutdownTimeout); err != nil {
+			return env, nil, err
+		}
+	}
+
+	if err := os.MkdirAll(p.dir(), 0755); err != nil {
+		return env, nil, err
+	}
+
+	if err := config(&env, file); err != nil {
+		return env, nil, err
+	}
+	if httpPort != 0 {
+		env.HttpPort = httpPort
+	}
+
+	if rpcPort != 0 {
+		env.RpcPort = rpcPort
+	}
+
+	path, err := p.override(file, env)
+	if err != nil {
+		return env, nil, err
+	}
+
+	current, _ := json.Marshal(state{Config: file, HttpPort: env.HttpPort, RpcPort: env.RpcPort})
+	if err := os.WriteFile(fmt.Sprintf("%s/profile.json", p.dir()), current, 0644); err != nil {
+		return env, nil, err
+	}
+
+	if err := NewPipeline(false).Run(Compile(p.binary(), false, "", "-race")); err != nil {
+		return env, nil, err
+	}
+
+	cmd := exec.Command(p.binary(), "run", path)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("APP_PORT=%d", env.HttpPort), fmt.Sprintf("GRPC_PORT=%d", env.RpcPort))
+
+	return env, cmd, nil
+}
+
+func (p Profile) start(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(p.PidFile(), []byte(strconv.Itoa(cmd.Process.Pid)), 0644); err != nil {
+		_ = cmd.Process.Kill()
+
+		return err
+	}
+
+	return nil
+}
+
+func (p Profile) dir() string {
+	if p == "" {
+		return ".bima"
+	}
+
+	return fmt.Sprintf("%s/%s", profiles, p)
+}
+
+func (p Profile) binary() string {
+	wd, _ := os.Getwd()
+
+	return fmt.Sprintf("%s/%s/bima", wd, p.dir())
+}
+
+func (p Profile) override(file string, env configs.Env) (string, error) {
+	ext := filepath.Ext(file)
+	if ext != ".yaml" && ext != ".json" {
+		return file, nil
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	path := fmt.Sprintf("%s/config%s", p.dir(), ext)
+	if ext == ".json" {
+		values := map[string]interface{}{}
+		if err := json.Unmarshal(content, &values); err != nil {
+			return "", err
+		}
+
+		values["http_port"] = env.HttpPort
+		values["rpc_port"] = env.RpcPort
+		content, err = json.MarshalIndent(values, "", "    ")
+	} else {
+		values := yaml.MapSlice{}
+		if err := yaml.Unmarshal(content, &values); err != nil {
+			return "", err
+		}
+
+		values = replace(values, "http_port", env.HttpPort)
+		values = replace(values, "rpc_port", env.RpcPort)
+		content, err = yaml.Marshal(values)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return path, os.WriteFile(path, content, 0644)
+}
+
+func (p Profile) owned(pid int) bool {
+	if !Alive(pid) {
+		return false
+	}
+
+	output, err := exec.Command("ps", "-o", "command=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return false
+	}
+
+	args := strings.Fields(string(output))
+	if len(args) < 2 || args[1] != "run" {
+		return false
+	}
+
+	binary, err := filepath.Abs(args[0])
+	if err != nil || !p.started(pid) {
+		return false
+	}
+
+	if p != "" {
+		return binary == p.binary()
+	}
+
+	if filepath.Base(binary) == "main" && strings.Contains(binary, "go-build") {
+		return true
+	}
+
+	wd, _ := os.Getwd()
+	separator := string(filepath.Separator)
+
+	return strings.HasPrefix(binary, wd+separator) && !strings.HasPrefix(binary, filepath.Join(wd, profiles)+separator)
+}
+
+func (p Profile) started(pid int) bool {
+	info, err := os.Stat(p.PidFile())
+	if err != nil {
+		return false
+	}
+
+	cmd := exec.Command("ps", "-o", "lstart=", "-p", strconv.Itoa(pid))
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	output, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+
+	start, err := time.ParseInLocation("Mon Jan 2 15:04:05 2006", strings.Join(strings.Fields(string(output)), " "), time.Local)
+	if err != nil {
+		return false
+	}
+
+	return !start.After(info.ModTime().Add(time.Second))
+}
+
+func replace(values yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	for k, v := range values {
+		if v.Key == key {
+			values[k].Value = value
+
+			return values
+		}
+	}
+
+	return append(values, yaml.MapItem{Key: key, Value: value})
+}
@@ -0,0 +1,129 @@
+package tool
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		profile Profile
+		valid   bool
+	}{
+		{name: "default", profile: "", valid: true},
+		{name: "plain", profile: "api-2_b", valid: true},
+		{name: "reserved", profile: "app", valid: false},
+		{name: "path", profile: "../api", valid: false},
+		{name: "space", profile: "api 2", valid: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.profile.Validate()
+			if (err == nil) != c.valid {
+				t.Fatalf("expect valid %t got %v", c.valid, err)
+			}
+
+			if err != nil && Code(err) != ExitUsage {
+				t.Fatalf("expect usage exit code got %d", Code(err))
+			}
+		})
+	}
+}
+
+func TestOwned(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chdir(t, dir)
+	if err := os.WriteFile("run", []byte("sleep 30\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile("serve", []byte("sleep 30\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	launch := func(p Profile, binary string, command string) int {
+		t.Helper()
+
+		if err := os.MkdirAll(filepath.Dir(p.PidFile()), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		cmd := &exec.Cmd{Path: "/bin/sh", Args: []string{binary, command}}
+		if err := p.start(cmd); err != nil {
+			t.Fatal(err)
+		}
+
+		t.Cleanup(func() {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+		})
+
+		return cmd.Process.Pid
+	}
+
+	api := Profile("api")
+	pid := launch(api, api.binary(), "run")
+	if !api.Running() {
+		t.Fatal("expect profile process to be owned")
+	}
+
+	if Profile("worker").owned(pid) {
+		t.Fatal("expect process of another profile not to be owned")
+	}
+
+	stale := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(api.PidFile(), stale, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	if api.Running() {
+		t.Fatal("expect process started after pid file to be treated as reused pid")
+	}
+
+	worker := Profile("worker")
+	launch(worker, worker.binary(), "serve")
+	if worker.Running() {
+		t.Fatal("expect process not started with run to be rejected")
+	}
+
+	launch(Profile(""), filepath.Join(dir, "bima"), "run")
+	if !Profile("").Running() {
+		t.Fatal("expect project binary to be owned by default profile")
+	}
+
+	launch(Profile(""), api.binary(), "run")
+	if Profile("").Running() {
+		t.Fatal("expect profile binary not to be owned by default profile")
+	}
+
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(api.PidFile(), []byte(strconv.Itoa(cmd.Process.Pid)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if api.Running() {
+		t.Fatal("expect exited process not to be running")
+	}
+
+	if err := api.Stop(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(api.PidFile()); !os.IsNotExist(err) {
+		t.Fatal("expect stale pid file to be removed")
+	}
+}
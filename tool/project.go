@@ -0,0 +1,145 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	"mvdan.cc/sh/expand"
+	"mvdan.cc/sh/interp"
+	"mvdan.cc/sh/syntax"
+)
+
+const project = "bima.yaml"
+
+type (
+	Project struct {
+		Tasks map[string]Chore `yaml:"tasks"`
+		Hooks map[string]Hook  `yaml:"hooks"`
+	}
+
+	Chore struct {
+		Description string            `yaml:"desc" json:"desc,omitempty"`
+		Cmds        []string          `yaml:"cmds" json:"cmds"`
+		Deps        []string          `yaml:"deps" json:"deps,omitempty"`
+		Env         map[string]string `yaml:"env" json:"env,omitempty"`
+		Dir         string            `yaml:"dir" json:"dir,omitempty"`
+	}
+)
+
+func LoadProject() (Project, error) {
+	p := Project{}
+	content, err := os.ReadFile(project)
+	if os.IsNotExist(err) {
+		return p, nil
+	}
+
+	if err != nil {
+		return p, err
+	}
+
+	if err := yaml.UnmarshalStrict(content, &p); err != nil {
+		return p, fmt.Errorf("invalid %s: %s", project, err.Error())
+	}
+
+	for step := range p.Hooks {
+		if !steps[step] {
+			return p, fmt.Errorf("invalid %s: unknown hook %s", project, step)
+		}
+	}
+
+	return p, nil
+}
+
+func (p Project) Names() []string {
+	names := make([]string, 0, len(p.Tasks))
+	for name := range p.Tasks {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (p Project) Task(name string, args []string) (Task, error) {
+	return p.task(name, args, map[string]bool{})
+}
+
+func (p Project) task(name string, args []string, visiting map[string]bool) (Task, error) {
+	chore, ok := p.Tasks[name]
+	if !ok {
+		return Task{}, fmt.Errorf("task %s is not defined in %s", name, project)
+	}
+
+	if visiting[name] {
+		return Task{}, fmt.Errorf("task %s has circular dependency", name)
+	}
+
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	needs := []Task{}
+	for _, dep := range chore.Deps {
+		t, err := p.task(dep, nil, visiting)
+		if err != nil {
+			return Task{}, err
+		}
+
+		needs = append(needs, t)
+	}
+
+	return Task{
+		Name:    name,
+		Message: fmt.Sprintf("Error running task %s", name),
+		Needs:   needs,
+		Action: func() error {
+			return chore.run(args)
+		},
+	}, nil
+}
+
+func (c Chore) run(args []string) error {
+	env := os.Environ()
+	keys := make([]string, 0, len(c.Env))
+	for key := range c.Env {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, fmt.Sprintf("%s=%s", key, c.Env[key]))
+	}
+
+	options := []func(*interp.Runner) error{
+		interp.Env(expand.ListEnviron(env...)),
+		interp.StdIO(os.Stdin, os.Stdout, os.Stderr),
+		interp.Params(append([]string{"--"}, args...)...),
+		interp.Module(interp.ModuleExec(execute)),
+	}
+
+	if c.Dir != "" {
+		options = append(options, interp.Dir(c.Dir))
+	}
+
+	for _, line := range c.Cmds {
+		cmd, err := syntax.NewParser().Parse(strings.NewReader(line), "")
+		if err != nil {
+			return err
+		}
+
+		runner, err := interp.New(options...)
+		if err != nil {
+			return err
+		}
+
+		if err := runner.Run(context.TODO(), cmd); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
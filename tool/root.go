@@ -0,0 +1,73 @@
+package tool
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/mod/modfile"
+)
+
+const framework = "github.com/bimalabs/framework/v4"
+
+func Root(dir string) (string, error) {
+	if dir == "" {
+		dir = "."
+	}
+
+	start, err := filepath.Abs(dir)
+	if err != nil {
+		return "", Fail(ExitEnvironment, err)
+	}
+
+	if info, err := os.Stat(start); err != nil || !info.IsDir() {
+		return "", Failf(ExitEnvironment, "project directory %s does not exist", start)
+	}
+
+	for current := start; ; {
+		if isProject(current) {
+			return current, nil
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+
+		current = parent
+	}
+
+	return "", Failf(ExitEnvironment, "%s is not inside a bima project, no go.mod requiring %s with %s found in it or its parents, use --project <dir> to point to project root", start, framework, c)
+}
+
+func Enter(dir string) error {
+	root, err := Root(dir)
+	if err != nil {
+		return err
+	}
+
+	return Fail(ExitEnvironment, os.Chdir(root))
+}
+
+func isProject(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, c)); err != nil {
+		return false
+	}
+
+	mod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return false
+	}
+
+	f, err := modfile.Parse("go.mod", mod, nil)
+	if err != nil {
+		return false
+	}
+
+	for _, r := range f.Require {
+		if r.Mod.Path == framework {
+			return true
+		}
+	}
+
+	return false
+}
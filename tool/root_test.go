@@ -0,0 +1,107 @@
+package tool
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRoot(t *testing.T) {
+	base, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	project := func(dir string, mod string, modules bool) string {
+		path := filepath.Join(base, dir)
+		if err := os.MkdirAll(filepath.Join(path, "configs"), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filepath.Join(path, "go.mod"), []byte(mod), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if modules {
+			if err := os.WriteFile(filepath.Join(path, c), []byte{}, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		return path
+	}
+
+	require := "module app\n\ngo 1.22\n\nrequire " + framework + " v4.3.5\n"
+	app := project("app", require, true)
+	unmarked := project("unmarked", require, false)
+	plain := project("plain", "module plain\n\ngo 1.22\n", true)
+
+	nested := filepath.Join(app, "modules", "todos", "protos")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		dir    string
+		expect string
+	}{
+		{name: "root", dir: app, expect: app},
+		{name: "nested", dir: nested, expect: app},
+		{name: "missing modules", dir: unmarked},
+		{name: "without framework", dir: plain},
+		{name: "not exist", dir: filepath.Join(base, "missing")},
+		{name: "outside", dir: base},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			root, err := Root(c.dir)
+			if c.expect == "" {
+				if err == nil {
+					t.Fatalf("expect error, got %s", root)
+				}
+
+				if code := Code(err); code != ExitEnvironment {
+					t.Fatalf("expect exit code %d, got %d", ExitEnvironment, code)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if root != c.expect {
+				t.Fatalf("expect %s, got %s", c.expect, root)
+			}
+		})
+	}
+
+	t.Run("relative", func(t *testing.T) {
+		chdir(t, nested)
+
+		root, err := Root("")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if root != app {
+			t.Fatalf("expect %s, got %s", app, root)
+		}
+	})
+
+	t.Run("enter", func(t *testing.T) {
+		chdir(t, base)
+
+		if err := Enter("app/modules"); err != nil {
+			t.Fatal(err)
+		}
+
+		wd, _ := os.Getwd()
+		if wd != app {
+			t.Fatalf("expect working directory %s, got %s", app, wd)
+		}
+	})
+}
@@ -0,0 +1,318 @@
+package tool
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bimalabs/framework/v4/configs"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v2"
+)
+
+const keyFile = ".bima/key"
+
+var encrypted = regexp.MustCompile(`^ENC\[([A-Za-z0-9+/=]+)\]$`)
+
+func Encrypt(file string, key string) error {
+	secret, err := secretKey(true)
+	if err != nil {
+		return err
+	}
+
+	return rewrite(file, key, func(value string) (string, error) {
+		if encrypted.MatchString(value) {
+			return value, nil
+		}
+
+		return seal(secret, value)
+	})
+}
+
+func Decrypt(file string, key string) error {
+	secret, err := secretKey(false)
+	if err != nil {
+		return err
+	}
+
+	return rewrite(file, key, func(value string) (string, error) {
+		return open(secret, value)
+	})
+}
+
+func Encrypted(file string) bool {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return false
+	}
+
+	return strings.Contains(string(content), "ENC[")
+}
+
+func reveal(value string) (string, error) {
+	if !encrypted.MatchString(value) {
+		return value, nil
+	}
+
+	secret, err := secretKey(false)
+	if err != nil {
+		return "", err
+	}
+
+	return open(secret, value)
+}
+
+func revealAll(values interface{}, path string) (interface{}, error) {
+	switch items := values.(type) {
+	case yaml.MapSlice:
+		for k, v := range items {
+			value, err := revealAll(v.Value, join(path, fmt.Sprint(v.Key)))
+			if err != nil {
+				return nil, err
+			}
+
+			items[k].Value = value
+		}
+	case map[string]interface{}:
+		for k, v := range items {
+			value, err := revealAll(v, join(path, k))
+			if err != nil {
+				return nil, err
+			}
+
+			items[k] = value
+		}
+	case string:
+		if !encrypted.MatchString(items) {
+			return items, nil
+		}
+
+		plain, err := reveal(items)
+		if err != nil {
+			return nil, err
+		}
+
+		if f := lookupPath(path); f != nil {
+			env := configs.Env{}
+			if f.set(&env, strings.TrimSpace(plain)) == nil {
+				return valueOf(env, path), nil
+			}
+		}
+
+		return plain, nil
+	}
+
+	return values, nil
+}
+
+func rewrite(file string, key string, transform func(value string) (string, error)) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	if Format(file) == "env" {
+		values, err := godotenv.Read(file)
+		if err != nil {
+			return err
+		}
+
+		value, ok := values[key]
+		if !ok {
+			return fmt.Errorf("key %s is not found in %s", key, file)
+		}
+
+		value, err = transform(value)
+		if err != nil {
+			return err
+		}
+
+		if !plain.MatchString(value) && !encrypted.MatchString(value) {
+			value = strconv.Quote(value)
+		}
+
+		line := regexp.MustCompile(fmt.Sprintf(`(?m)^(\s*(?:export\s+)?%s\s*=\s*).*$`, regexp.QuoteMeta(key)))
+
+		return os.WriteFile(file, line.ReplaceAll(content, []byte(fmt.Sprintf("${1}%s", strings.ReplaceAll(value, "$", "$$")))), 0644)
+	}
+
+	path := key
+	if f := lookup(key); f != nil {
+		path = f.path
+	}
+
+	entries, err := read(file)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for k, e := range entries {
+		if e.path != path {
+			continue
+		}
+
+		value, err := transform(fmt.Sprint(e.value))
+		if err != nil {
+			return err
+		}
+
+		entries[k].value = value
+		if f := lookupPath(path); f != nil && !encrypted.MatchString(value) {
+			env := configs.Env{}
+			if f.set(&env, value) == nil {
+				entries[k].value = valueOf(env, path)
+			}
+		}
+
+		found = true
+	}
+
+	if !found {
+		return fmt.Errorf("key %s is not found in %s", key, file)
+	}
+
+	if Format(file) == "json" {
+		content, err = ordered(nest(entries))
+	} else {
+		content, err = yaml.Marshal(nest(entries))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, content, 0644)
+}
+
+func decrypt(content []byte, format string) ([]byte, error) {
+	if format == "env" || !strings.Contains(string(content), "ENC[") {
+		return content, nil
+	}
+
+	if format == "json" {
+		values := map[string]interface{}{}
+		if err := json.Unmarshal(content, &values); err != nil {
+			return nil, err
+		}
+
+		if _, err := revealAll(values, ""); err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(values)
+	}
+
+	values := yaml.MapSlice{}
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return nil, err
+	}
+
+	if _, err := revealAll(values, ""); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(values)
+}
+
+func secretKey(create bool) ([]byte, error) {
+	encoded := os.Getenv("BIMA_CONFIG_KEY")
+	if encoded == "" {
+		path := os.Getenv("BIMA_CONFIG_KEY_FILE")
+		if path == "" {
+			path = keyFile
+		}
+
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) && create {
+			return generateKey(path)
+		}
+
+		if err != nil {
+			return nil, errors.New("config key not found, set BIMA_CONFIG_KEY or BIMA_CONFIG_KEY_FILE")
+		}
+
+		encoded = strings.TrimSpace(string(content))
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("config key must be base64 encoded 32 bytes")
+	}
+
+	return key, nil
+}
+
+func generateKey(path string) ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(".bima", 0755); err != nil {
+		return nil, err
+	}
+
+	return key, os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600)
+}
+
+func seal(key []byte, value string) (string, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("ENC[%s]", base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(value), nil))), nil
+}
+
+func open(key []byte, value string) (string, error) {
+	match := encrypted.FindStringSubmatch(value)
+	if match == nil {
+		return value, nil
+	}
+
+	content, err := base64.StdEncoding.DecodeString(match[1])
+	if err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	if len(content) < gcm.NonceSize() {
+		return "", errors.New("encrypted value is corrupted")
+	}
+
+	plain, err := gcm.Open(nil, content[:gcm.NonceSize()], content[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("unable to decrypt value, config key does not match")
+	}
+
+	return string(plain), nil
+}
@@ -0,0 +1,164 @@
+package tool
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bimalabs/framework/v4/configs"
+)
+
+func key(t *testing.T) {
+	t.Helper()
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("BIMA_CONFIG_KEY", base64.StdEncoding.EncodeToString(secret))
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	key(t)
+
+	cases := []struct {
+		name    string
+		file    string
+		content string
+		key     string
+		expect  configs.Env
+	}{
+		{name: "env", file: ".env", content: "APP_PORT=7777\nDB_PASSWORD=\"s3cr3t pass\"\n", key: "DB_PASSWORD", expect: configs.Env{HttpPort: 7777, Db: configs.Db{Password: "s3cr3t pass"}}},
+		{name: "env number", file: ".env", content: "APP_PORT=7777\n", key: "APP_PORT", expect: configs.Env{HttpPort: 7777}},
+		{name: "yaml key", file: "app.yaml", content: "http_port: 7777\ndatabase:\n  password: s3cr3t\n", key: "DB_PASSWORD", expect: configs.Env{HttpPort: 7777, Db: configs.Db{Password: "s3cr3t"}}},
+		{name: "yaml path", file: "app.yaml", content: "http_port: 7777\nsecret: s3cr3t\n", key: "secret", expect: configs.Env{HttpPort: 7777, Secret: "s3cr3t"}},
+		{name: "yaml number", file: "app.yaml", content: "http_port: 7777\n", key: "http_port", expect: configs.Env{HttpPort: 7777}},
+		{name: "json numeric secret", file: "app.json", content: `{"http_port": 7777, "secret": "007"}`, key: "secret", expect: configs.Env{HttpPort: 7777, Secret: "007"}},
+		{name: "yaml numeric secret", file: "app.yaml", content: "secret: \"1e3\"\ndatabase:\n  password: \"true\"\n", key: "secret", expect: configs.Env{Secret: "1e3", Db: configs.Db{Password: "true"}}},
+		{name: "env numeric secret", file: ".env", content: "APP_SECRET=007\n", key: "APP_SECRET", expect: configs.Env{Secret: "007"}},
+		{name: "json", file: "app.json", content: `{"http_port": 7777, "database": {"port": 5432, "password": "s3cr3t"}}`, key: "DB_PORT", expect: configs.Env{HttpPort: 7777, Db: configs.Db{Port: 5432, Password: "s3cr3t"}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			file := write(t, c.file, c.content)
+			if err := Encrypt(file, c.key); err != nil {
+				t.Fatal(err)
+			}
+
+			if !Encrypted(file) {
+				t.Fatalf("expect %s to be encrypted", file)
+			}
+
+			env := configs.Env{}
+			if err := load(&env, file); err != nil {
+				t.Fatal(err)
+			}
+
+			if env != c.expect {
+				t.Fatalf("expect encrypted config to load as %+v, got %+v", c.expect, env)
+			}
+
+			if problems := inspect(&configs.Env{}, file); len(problems) != 0 {
+				t.Fatalf("expect no problem, got %+v", problems)
+			}
+
+			if err := Decrypt(file, c.key); err != nil {
+				t.Fatal(err)
+			}
+
+			if Encrypted(file) {
+				t.Fatalf("expect %s to be decrypted", file)
+			}
+
+			env = configs.Env{}
+			if err := load(&env, file); err != nil {
+				t.Fatal(err)
+			}
+
+			if env != c.expect {
+				t.Fatalf("expect decrypted config to load as %+v, got %+v", c.expect, env)
+			}
+		})
+	}
+}
+
+func TestEncryptErrors(t *testing.T) {
+	key(t)
+
+	file := write(t, ".env", "APP_PORT=7777\n")
+	if err := Encrypt(file, "DB_PASSWORD"); err == nil {
+		t.Fatal("expect missing key to fail")
+	}
+
+	if err := Encrypt(file, "APP_PORT"); err != nil {
+		t.Fatal(err)
+	}
+
+	key(t)
+	if err := load(&configs.Env{}, file); err == nil {
+		t.Fatal("expect different config key to fail")
+	}
+}
+
+func TestMaterializeSecrets(t *testing.T) {
+	key(t)
+
+	for _, k := range []string{"APP_PORT", "APP_SECRET", "DB_PASSWORD", "DB_USER"} {
+		t.Setenv(k, "")
+		os.Unsetenv(k)
+	}
+
+	cases := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{name: "env", file: ".env", content: "APP_PORT=7777\nAPP_SECRET=app-s3cr3t\nDB_PASSWORD=db-s3cr3t\nDB_USER=bima\n"},
+		{name: "yaml", file: "app.yaml", content: "http_port: 7777\nsecret: app-s3cr3t\ndatabase:\n  user: bima\n  password: db-s3cr3t\n"},
+		{name: "json", file: "app.json", content: `{"http_port": 7777, "secret": "app-s3cr3t", "database": {"user": "bima", "password": "db-s3cr3t"}}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			file := write(t, c.file, c.content)
+			for _, k := range []string{"APP_SECRET", "DB_PASSWORD"} {
+				if err := Encrypt(file, k); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			config, err := Materialize([]string{file}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer config.Close()
+
+			if config.File == file {
+				t.Fatal("expect encrypted config to be materialized")
+			}
+
+			content, err := os.ReadFile(config.File)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, plain := range []string{"app-s3cr3t", "db-s3cr3t"} {
+				if strings.Contains(string(content), plain) {
+					t.Fatalf("expect materialized config not to contain %s, got %s", plain, content)
+				}
+			}
+
+			if !strings.Contains(string(content), "DB_USER=bima") {
+				t.Fatalf("expect materialized config to contain plain values, got %s", content)
+			}
+
+			if os.Getenv("APP_SECRET") != "app-s3cr3t" || os.Getenv("DB_PASSWORD") != "db-s3cr3t" {
+				t.Fatal("expect decrypted values to be passed through environment")
+			}
+		})
+	}
+}
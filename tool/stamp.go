@@ -0,0 +1,165 @@
+package tool
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bimalabs/cli/bima"
+	"github.com/fatih/color"
+	"golang.org/x/mod/modfile"
+)
+
+var variables = []string{"version", "commit", "buildTime", "cliVersion", "frameworkVersion"}
+
+type Stamp struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	Cli       string `json:"cli"`
+	Framework string `json:"framework"`
+	Pinned    bool   `json:"-"`
+}
+
+func NewStamp(version string, release bool) Stamp {
+	if version == "" {
+		version = revision("describe", "--tags", "--always", "--dirty")
+	}
+
+	if version == "" {
+		version = "dev"
+	}
+
+	commit := revision("rev-parse", "--short", "HEAD")
+	if commit == "" {
+		commit = "unknown"
+	}
+
+	stamp := Stamp{
+		Version:   version,
+		Commit:    commit,
+		BuildTime: time.Now().UTC().Format(time.RFC3339),
+		Cli:       bima.Version,
+		Framework: Framework(),
+	}
+
+	if !release {
+		return stamp
+	}
+
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		epoch = revision("log", "-1", "--format=%ct")
+	}
+
+	if seconds, err := strconv.ParseInt(epoch, 10, 64); err == nil {
+		stamp.BuildTime = time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+		stamp.Pinned = true
+	}
+
+	return stamp
+}
+
+func (s Stamp) Flags() string {
+	values := map[string]string{
+		"version":          s.Version,
+		"commit":           s.Commit,
+		"buildTime":        s.BuildTime,
+		"cliVersion":       s.Cli,
+		"frameworkVersion": s.Framework,
+	}
+
+	flags := []string{}
+	for _, name := range variables {
+		pair := fmt.Sprintf("main.%s=%s", name, values[name])
+		if strings.Contains(pair, "'") {
+			flags = append(flags, fmt.Sprintf(`-X "%s"`, pair))
+
+			continue
+		}
+
+		flags = append(flags, fmt.Sprintf("-X '%s'", pair))
+	}
+
+	return strings.Join(flags, " ")
+}
+
+func (s Stamp) Undeclared() []string {
+	declared := map[string]bool{}
+	file, err := parser.ParseFile(token.NewFileSet(), "cmd/main.go", nil, 0)
+	if err == nil {
+		for _, d := range file.Decls {
+			decl, ok := d.(*ast.GenDecl)
+			if !ok || decl.Tok != token.VAR {
+				continue
+			}
+
+			for _, spec := range decl.Specs {
+				for _, name := range spec.(*ast.ValueSpec).Names {
+					declared[name.Name] = true
+				}
+			}
+		}
+	}
+
+	missing := []string{}
+	for _, name := range variables {
+		if !declared[name] {
+			missing = append(missing, name)
+		}
+	}
+
+	return missing
+}
+
+func (s Stamp) Print() {
+	fmt.Printf("Version: %s\n", s.Version)
+	fmt.Printf("Commit: %s\n", s.Commit)
+	fmt.Printf("Build Time: %s\n", s.BuildTime)
+	fmt.Printf("Cli: %s\n", s.Cli)
+	fmt.Printf("Framework: %s\n", s.Framework)
+	for _, name := range s.Undeclared() {
+		color.New(color.FgYellow).Printf("Warning: main.%s is not declared in cmd/main.go, it will not be stamped\n", name)
+	}
+}
+
+func Framework() string {
+	wd, _ := os.Getwd()
+	var path strings.Builder
+
+	path.WriteString(wd)
+	path.WriteString("/go.mod")
+
+	mod, err := os.ReadFile(path.String())
+	if err != nil {
+		return "unknown"
+	}
+
+	f, err := modfile.Parse(path.String(), mod, nil)
+	if err != nil {
+		return "unknown"
+	}
+
+	for _, v := range f.Require {
+		if v.Mod.Path == framework {
+			return v.Mod.Version
+		}
+	}
+
+	return "unknown"
+}
+
+func revision(args ...string) string {
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(output))
+}
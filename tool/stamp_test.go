@@ -0,0 +1,56 @@
+package tool
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestFlags(t *testing.T) {
+	cases := []struct {
+		name    string
+		version string
+		expect  string
+	}{
+		{name: "plain", version: "v1.0.0", expect: "-X 'main.version=v1.0.0'"},
+		{name: "space", version: "v1.0.0 beta", expect: "-X 'main.version=v1.0.0 beta'"},
+		{name: "single quote", version: "it's", expect: `-X "main.version=it's"`},
+		{name: "flag injection", version: "v1 -X main.commit=x", expect: "-X 'main.version=v1 -X main.commit=x'"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			flags := Stamp{Version: c.version}.Flags()
+			if !strings.HasPrefix(flags, c.expect+" ") {
+				t.Fatalf("expect %q to start with %q", flags, c.expect)
+			}
+
+			out, err := exec.Command("sh", "-c", "printf %s "+escape(flags)).Output()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(out) != flags {
+				t.Fatalf("expect shell to pass %q as single argument, got %q", flags, out)
+			}
+		})
+	}
+}
+
+func TestUndeclared(t *testing.T) {
+	chdir(t, t.TempDir())
+	if err := os.Mkdir("cmd", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	content := "package main\n\nvar (\n\tversion string\n\tcommit  string\n)\n\nvar buildTime = \"\"\n\nfunc main() {}\n"
+	if err := os.WriteFile("cmd/main.go", []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	missing := Stamp{}.Undeclared()
+	if strings.Join(missing, ",") != "cliVersion,frameworkVersion" {
+		t.Fatalf("expect cliVersion and frameworkVersion to be undeclared, got %v", missing)
+	}
+}
@@ -0,0 +1,116 @@
+package tool
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bimalabs/framework/v4/configs"
+)
+
+type (
+	Port struct {
+		Name      string `json:"name"`
+		Port      int    `json:"port"`
+		Listening bool   `json:"listening"`
+	}
+
+	Status struct {
+		Profile string     `json:"profile,omitempty"`
+		Pid     int        `json:"pid"`
+		Running bool       `json:"running"`
+		Started *time.Time `json:"started_at,omitempty"`
+		Uptime  string     `json:"uptime,omitempty"`
+		Ports   []Port     `json:"ports"`
+	}
+)
+
+func (p Profile) Inspect(env configs.Env) (Status, error) {
+	current := state{}
+	if p != "" {
+		content, _ := os.ReadFile(fmt.Sprintf("%s/profile.json", p.dir()))
+		_ = json.Unmarshal(content, &current)
+	}
+
+	if current.HttpPort != 0 {
+		env.HttpPort = current.HttpPort
+	}
+
+	if current.RpcPort != 0 {
+		env.RpcPort = current.RpcPort
+	}
+
+	status := Status{
+		Profile: string(p),
+		Pid:     p.Pid(),
+		Ports: []Port{
+			{Name: "http", Port: env.HttpPort},
+			{Name: "grpc", Port: env.RpcPort},
+		},
+	}
+
+	status.Running = status.Pid != 0 && p.owned(status.Pid)
+	if status.Running {
+		if uptime, err := elapsed(status.Pid); err == nil {
+			started := time.Now().Add(-uptime).Truncate(time.Second)
+			status.Started = &started
+			status.Uptime = uptime.String()
+		}
+	}
+
+	for k, v := range status.Ports {
+		status.Ports[k].Listening = listening(v.Port)
+	}
+
+	return status, nil
+}
+
+func listening(port int) bool {
+	if port == 0 {
+		return false
+	}
+
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("127.0.0.1:%d", port), time.Second)
+	if err != nil {
+		return false
+	}
+
+	_ = conn.Close()
+
+	return true
+}
+
+func elapsed(pid int) (time.Duration, error) {
+	output, err := exec.Command("ps", "-o", "etime=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return 0, err
+	}
+
+	var days int
+	value := strings.TrimSpace(string(output))
+	if i := strings.Index(value, "-"); i != -1 {
+		days, err = strconv.Atoi(value[:i])
+		if err != nil {
+			return 0, err
+		}
+
+		value = value[i+1:]
+	}
+
+	var seconds int
+	for _, v := range strings.Split(value, ":") {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, err
+		}
+
+		seconds = seconds*60 + n
+	}
+
+	return time.Duration(days*86400+seconds) * time.Second, nil
+}
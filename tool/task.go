@@ -0,0 +1,237 @@
+package tool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"mvdan.cc/sh/interp"
+)
+
+type (
+	Task struct {
+		Name    string
+		Message string
+		Needs   []Task
+		Inputs  func() []string
+		Outputs func() []string
+		Action  func() error
+		hook    string
+		env     map[string]string
+		code    int
+	}
+
+	Pipeline struct {
+		cached   bool
+		done     map[string]bool
+		manifest map[string]string
+	}
+
+	Failure struct {
+		Task    string
+		Message string
+		Code    int
+		Err     error
+	}
+)
+
+func NewPipeline(cached bool) *Pipeline {
+	manifest := map[string]string{}
+	if content, err := os.ReadFile(cache); err == nil && cached {
+		_ = json.Unmarshal(content, &manifest)
+	}
+
+	return &Pipeline{cached: cached, done: map[string]bool{}, manifest: manifest}
+}
+
+func Clean() Task {
+	return Task{
+		Name:    "clean",
+		Message: "Error cleaning dependencies",
+		Inputs: func() []string {
+			return append([]string{"go.mod", "go.sum", "configs", "protos"}, folders()...)
+		},
+		Action: clean,
+		code:   ExitToolchain,
+	}
+}
+
+func Tidy() Task {
+	task := Clean()
+	task.Name = "tidy"
+	task.Inputs = nil
+
+	return task
+}
+
+func Dump() Task {
+	return Task{
+		Name:    "dump",
+		Message: "Error updating services container",
+		Inputs: func() []string {
+			return append([]string{"go.mod", "configs"}, folders()...)
+		},
+		Outputs: func() []string {
+			return []string{"generated"}
+		},
+		Action: dump,
+		code:   ExitGeneration,
+		hook:   "dump",
+	}
+}
+
+func Genproto() Task {
+	return Task{
+		Name:    "genproto",
+		Message: "Error generate codes from proto files",
+		Inputs: func() []string {
+			return []string{"protos", "libs"}
+		},
+		Outputs: func() []string {
+			return []string{"swaggers"}
+		},
+		Action: genproto,
+		code:   ExitGeneration,
+		hook:   "genproto",
+	}
+}
+
+func Update() Task {
+	return Task{
+		Name:    "update",
+		Message: "Error updating dependencies",
+		Action:  update,
+		code:    ExitToolchain,
+	}
+}
+
+func Build(name string, debug bool, ldflags string, flags ...string) Task {
+	task := Compile(name, debug, ldflags, flags...)
+	task.Needs = []Task{Clean(), Dump()}
+
+	return task
+}
+
+func Compile(name string, debug bool, ldflags string, flags ...string) Task {
+	return Task{
+		Name:    "build",
+		Message: "Error building application",
+		Action: func() error {
+			return build(name, debug, ldflags, flags)
+		},
+		hook: "build",
+		code: ExitBuild,
+		env: map[string]string{
+			"BIMA_BUILD_TARGET": fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+			"BIMA_BUILD_OUTPUT": name,
+		},
+	}
+}
+
+func (p *Pipeline) Run(tasks ...Task) error {
+	for _, t := range tasks {
+		if err := p.run(t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *Pipeline) run(t Task) error {
+	if p.done[t.Name] {
+		trace(t.Name, "skipped, already done")
+
+		return nil
+	}
+
+	for _, n := range t.Needs {
+		if err := p.run(n); err != nil {
+			return err
+		}
+	}
+
+	p.done[t.Name] = true
+	if p.cached && t.Inputs != nil && p.manifest[t.Name] != "" && p.manifest[t.Name] == t.fingerprint() {
+		trace(t.Name, "skipped, inputs unchanged")
+
+		return nil
+	}
+
+	action := t.Action
+	if t.hook != "" {
+		action = func() error {
+			return hook(t.hook, t.env, t.Action)
+		}
+	}
+
+	start := time.Now()
+	if err := action(); err != nil {
+		trace(t.Name, fmt.Sprintf("failed after %s", time.Since(start).Round(time.Millisecond)))
+		if p.cached && t.Inputs != nil {
+			delete(p.manifest, t.Name)
+			_ = save(p.manifest)
+		}
+
+		return &Failure{Task: t.Name, Message: t.Message, Code: t.code, Err: err}
+	}
+
+	trace(t.Name, fmt.Sprintf("done in %s", time.Since(start).Round(time.Millisecond)))
+	if !p.cached || t.Inputs == nil {
+		return nil
+	}
+
+	p.manifest[t.Name] = t.fingerprint()
+
+	return save(p.manifest)
+}
+
+func (t Task) fingerprint() string {
+	paths := t.Inputs()
+	if t.Outputs != nil {
+		paths = append(paths, t.Outputs()...)
+	}
+
+	return fingerprint(paths)
+}
+
+func NewFailure(code int, message string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &Failure{Message: message, Code: code, Err: err}
+}
+
+func (f *Failure) Error() string {
+	return f.Err.Error()
+}
+
+func (f *Failure) ExitCode() int {
+	if f.Code != 0 {
+		return f.Code
+	}
+
+	var status interp.ExitStatus
+	if errors.As(f.Err, &status) && status != 0 {
+		return int(status)
+	}
+
+	return 1
+}
+
+func (f *Failure) Unwrap() error {
+	return f.Err
+}
+
+func Reason(err error) string {
+	var failure *Failure
+	if errors.As(err, &failure) && failure.Message != "" {
+		return failure.Message
+	}
+
+	return err.Error()
+}
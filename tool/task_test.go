@@ -0,0 +1,127 @@
+package tool
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPipelineCache(t *testing.T) {
+	chdir(t, t.TempDir())
+
+	runs := 0
+	task := Task{
+		Name: "generate",
+		Inputs: func() []string {
+			return []string{"input"}
+		},
+		Outputs: func() []string {
+			return []string{"output"}
+		},
+		Action: func() error {
+			runs++
+
+			return os.WriteFile("output", []byte("generated"), 0644)
+		},
+	}
+
+	if err := os.WriteFile("input", []byte("source"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		change func() error
+		runs   int
+	}{
+		{name: "first run", change: func() error { return nil }, runs: 1},
+		{name: "unchanged", change: func() error { return nil }, runs: 1},
+		{name: "input changed", change: func() error { return os.WriteFile("input", []byte("changed"), 0644) }, runs: 2},
+		{name: "output removed", change: func() error { return os.Remove("output") }, runs: 3},
+		{name: "output modified", change: func() error { return os.WriteFile("output", []byte("edited"), 0644) }, runs: 4},
+		{name: "unchanged again", change: func() error { return nil }, runs: 4},
+	}
+
+	for _, c := range cases {
+		if err := c.change(); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := NewPipeline(true).Run(task); err != nil {
+			t.Fatal(err)
+		}
+
+		if runs != c.runs {
+			t.Fatalf("%s: expect %d runs, got %d", c.name, c.runs, runs)
+		}
+	}
+
+	if err := NewPipeline(false).Run(task); err != nil {
+		t.Fatal(err)
+	}
+
+	if runs != 5 {
+		t.Fatalf("expect uncached pipeline to run, got %d runs", runs)
+	}
+}
+
+func TestBuildTasks(t *testing.T) {
+	cases := []struct {
+		name  string
+		task  Task
+		needs []string
+	}{
+		{name: "build", task: Build("app", false, ""), needs: []string{"clean", "dump"}},
+		{name: "compile", task: Compile("app", false, "", "-race")},
+		{name: "debug", task: Compile("app", true, "")},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			needs := []string{}
+			for _, n := range c.task.Needs {
+				needs = append(needs, n.Name)
+			}
+
+			if strings.Join(needs, ",") != strings.Join(c.needs, ",") {
+				t.Fatalf("expect needs %v, got %v", c.needs, needs)
+			}
+
+			if c.task.Name != "build" || c.task.hook != "build" || c.task.code != ExitBuild {
+				t.Fatalf("expect build task with build hook, got %+v", c.task)
+			}
+
+			if c.task.env["BIMA_BUILD_OUTPUT"] != "app" {
+				t.Fatalf("expect build output in hook env, got %v", c.task.env)
+			}
+		})
+	}
+}
+
+func TestPipelineDedup(t *testing.T) {
+	runs := []string{}
+	task := func(name string) Task {
+		return Task{Name: name, Action: func() error {
+			runs = append(runs, name)
+
+			return nil
+		}}
+	}
+
+	clean := task("clean")
+	tidy := task("tidy")
+	dump := task("dump")
+	dump.Needs = []Task{clean}
+
+	if err := NewPipeline(false).Run(task("genproto"), clean, dump, clean, tidy); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(runs, ",") != "genproto,clean,dump,tidy" {
+		t.Fatalf("expect repeated clean to run once and tidy after dump, got %v", runs)
+	}
+
+	if Tidy().Name == Clean().Name || Tidy().Inputs != nil {
+		t.Fatal("expect tidy to be uncached task with its own name")
+	}
+}
@@ -1,119 +1,115 @@
 package tool
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"reflect"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/bimalabs/cli/bima"
 	"github.com/bimalabs/framework/v4/configs"
 	"github.com/bimalabs/generators"
-	"github.com/briandowns/spinner"
 	"github.com/fatih/color"
 	"github.com/gertd/go-pluralize"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing/object"
-	"github.com/joho/godotenv"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
-	"gopkg.in/yaml.v2"
 	"mvdan.cc/sh/interp"
 	"mvdan.cc/sh/syntax"
 )
 
-type (
-	command string
-	util    string
-)
+type command string
 
 func Pid() int {
-	content, err := os.ReadFile(".pid")
-	if err != nil {
-		return 0
-	}
+	return Profile("").Pid()
+}
 
-	pid, err := strconv.Atoi(string(content))
+func Alive(pid int) bool {
+	process, err := os.FindProcess(pid)
 	if err != nil {
-		return 0
+		return false
 	}
 
-	return pid
+	return process.Signal(syscall.Signal(0)) == nil
 }
 
-func Debug(ctx context.Context, pid int) error {
-	cmd, _ := syntax.NewParser().Parse(strings.NewReader(fmt.Sprintf("dlv attach %d --listen=:16517 --headless --api-version=2 --log", pid)), "")
-	runner, _ := interp.New(interp.Env(nil), interp.StdIO(nil, os.Stdout, os.Stdout))
+func terminate(pid int, grace time.Duration) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
 
-	return runner.Run(ctx, cmd)
-}
+	if err := process.Signal(syscall.SIGTERM); err != nil {
+		return process.Kill()
+	}
 
-func Call(name string, args ...interface{}) error {
-	in := make([]reflect.Value, len(args))
-	for k, v := range args {
-		in[k] = reflect.ValueOf(v)
+	deadline := time.Now().Add(grace)
+	for time.Now().Before(deadline) {
+		if !Alive(pid) {
+			return nil
+		}
+
+		time.Sleep(100 * time.Millisecond)
 	}
 
-	c := util(name)
-	returns := reflect.ValueOf(c).MethodByName(cases.Title(language.English).String(string(c))).Call(in)
-	if len(returns) > 1 {
-		return nil
+	return process.Kill()
+}
+
+func (c command) run(args ...interface{}) error {
+	if !bima.Quiet {
+		return c.pipe(os.Stdout, args...)
 	}
 
-	v := returns[0]
-	err, ok := v.Interface().(error)
-	if !ok {
-		return nil
+	var output bytes.Buffer
+	err := c.pipe(&output, args...)
+	if err != nil {
+		_, _ = os.Stderr.Write(output.Bytes())
 	}
 
 	return err
 }
 
-func (c command) run(args ...interface{}) error {
+func (c command) pipe(output io.Writer, args ...interface{}) error {
 	f := fmt.Sprintf(string(c), args...)
 	cmd, _ := syntax.NewParser().Parse(strings.NewReader(f), "")
-	runner, _ := interp.New(interp.Env(nil), interp.StdIO(nil, os.Stdout, os.Stdout))
+	runner, _ := interp.New(interp.Env(nil), interp.StdIO(nil, output, output), interp.Module(interp.ModuleExec(execute)))
 
 	return runner.Run(context.TODO(), cmd)
 }
 
-func (u util) Makesure(protoc int, protocGo int, protocGRpc int) error {
-	progress := spinner.New(spinner.CharSets[bima.SpinerIndex], bima.Duration)
-	progress.Suffix = " Checking toolchain installment... "
+func Makesure(protoc int, protocGo int, protocGRpc int) error {
+	progress := Spinner(" Checking toolchain installment... ")
 	progress.Start()
 
-	if err := u.Clean(); err != nil {
+	if err := NewPipeline(false).Run(Clean()); err != nil {
 		progress.Stop()
-		color.New(color.FgRed).Println("Error cleaning dependencies")
 
 		return err
 	}
 
 	_, err := exec.LookPath("dlv")
 	if err != nil {
-		output, err := exec.Command("go", "install", "github.com/go-delve/delve/cmd/dlv@latest").CombinedOutput()
+		output, err := call(exec.Command("go", "install", "github.com/go-delve/delve/cmd/dlv@latest"))
 		if err != nil {
 			progress.Stop()
-			color.New(color.FgRed).Println("Error install go debugger: ", output)
 
-			return err
+			return NewFailure(ExitToolchain, "Error install go debugger", errors.New(strings.TrimSpace(string(output))))
 		}
 	}
 
 	protocVersion := 0
-	output, err := exec.Command("protoc", "--version").CombinedOutput()
+	output, err := call(exec.Command("protoc", "--version"))
 	if err != nil {
 		progress.Stop()
-		color.New(color.FgRed).Printf("%s is not installed\n", color.New(color.FgRed, color.Bold).Sprint("protoc"))
 
-		return err
+		return Failf(ExitEnvironment, "protoc is not installed")
 	}
 
 	vSlice := strings.Split(string(output), " ")
@@ -128,7 +124,7 @@ func (u util) Makesure(protoc int, protocGo int, protocGRpc int) error {
 	}
 
 	protocGoVersion := 0
-	output, _ = exec.Command("protoc-gen-go", "--version").CombinedOutput()
+	output, _ = call(exec.Command("protoc-gen-go", "--version"))
 	vSlice = strings.Split(string(output), " ")
 	if len(vSlice) > 1 {
 		vSlice[1] = strings.TrimPrefix(vSlice[1], "v")
@@ -142,7 +138,7 @@ func (u util) Makesure(protoc int, protocGo int, protocGRpc int) error {
 	}
 
 	protocGRpcVersion := 0
-	output, _ = exec.Command("protoc-gen-go-grpc", "--version").CombinedOutput()
+	output, _ = call(exec.Command("protoc-gen-go-grpc", "--version"))
 	vSlice = strings.Split(string(output), " ")
 	if len(vSlice) > 1 {
 		vSlice = strings.Split(vSlice[1], ".")
@@ -154,39 +150,49 @@ func (u util) Makesure(protoc int, protocGo int, protocGRpc int) error {
 		}
 	}
 
+	status := map[string]interface{}{
+		"protoc":             semver(protocVersion),
+		"protoc_gen_go":      semver(protocGoVersion),
+		"protoc_gen_go_grpc": semver(protocGRpcVersion),
+		"installed":          true,
+		"updated":            false,
+	}
+
 	if protocVersion >= protoc && protocGoVersion >= protocGo && protocGRpcVersion >= protocGRpc {
 		progress.Stop()
 		color.New(color.FgGreen).Println("Toolchain is already installed")
 
-		return nil
+		return Emit(status)
 	}
 
 	progress.Stop()
 
-	progress = spinner.New(spinner.CharSets[bima.SpinerIndex], bima.Duration)
-	progress.Suffix = " Try to install/update to latest toolchain... "
+	progress = Spinner(" Try to install/update to latest toolchain... ")
 	progress.Start()
-	err = u.toolchain()
+	err = toolchain()
 	if err != nil {
 		progress.Stop()
-		color.New(color.FgRed).Println("Error installing toolchain")
 
-		return err
+		return NewFailure(ExitToolchain, "Error installing toolchain", err)
 	}
 
 	progress.Stop()
 	color.New(color.FgGreen).Println("Toolchain installed")
+	status["updated"] = true
 
-	return nil
+	return Emit(status)
+}
+
+func semver(version int) string {
+	return fmt.Sprintf("%d.%d.%d", version/10_000, version/100%100, version%100)
 }
 
-func (u util) Upgrade(version string) error {
+func Upgrade(version string) error {
 	temp := strings.TrimSuffix(os.TempDir(), "/")
 	wd := fmt.Sprintf("%s/bima", temp)
 	os.RemoveAll(wd)
 
-	progress := spinner.New(spinner.CharSets[bima.SpinerIndex], bima.Duration)
-	progress.Suffix = " Checking new update... "
+	progress := Spinner(" Checking new update... ")
 	progress.Start()
 
 	repository, err := git.PlainClone(wd, false, &git.CloneOptions{
@@ -195,9 +201,8 @@ func (u util) Upgrade(version string) error {
 	})
 	if err != nil {
 		progress.Stop()
-		color.New(color.FgRed).Println(err)
 
-		return nil
+		return Fail(ExitEnvironment, err)
 	}
 
 	var (
@@ -208,9 +213,8 @@ func (u util) Upgrade(version string) error {
 	tags, err := repository.TagObjects()
 	if err != nil {
 		progress.Stop()
-		color.New(color.FgRed).Println(err)
 
-		return nil
+		return Fail(ExitEnvironment, err)
 	}
 
 	_ = tags.ForEach(func(t *object.Tag) error {
@@ -231,32 +235,29 @@ func (u util) Upgrade(version string) error {
 
 	progress.Stop()
 
-	progress = spinner.New(spinner.CharSets[bima.SpinerIndex], bima.Duration)
-	progress.Suffix = " Updating Bima cli... "
+	progress = Spinner(" Updating Bima cli... ")
 	progress.Start()
 
 	cmd := exec.Command("git", "checkout", latest)
 	cmd.Dir = wd
-	err = cmd.Run()
+	_, err = call(cmd)
 	if err != nil {
 		progress.Stop()
-		color.New(color.FgRed).Println("Error checkout to latest tag")
 
-		return nil
+		return NewFailure(ExitEnvironment, "Error checkout to latest tag", err)
 	}
 
 	cmd = exec.Command("go", "get")
 	cmd.Dir = wd
-	_ = cmd.Run()
+	_, _ = call(cmd)
 
 	cmd = exec.Command("go", "build", "-o", "bima-cli")
 	cmd.Dir = wd
-	output, err := cmd.CombinedOutput()
+	output, err := call(cmd)
 	if err != nil {
 		progress.Stop()
-		color.New(color.FgRed).Println(string(output))
 
-		return err
+		return NewFailure(ExitBuild, "Error building bima cli", errors.New(strings.TrimSpace(string(output))))
 	}
 
 	binPath := os.Getenv("GOBIN")
@@ -265,11 +266,11 @@ func (u util) Upgrade(version string) error {
 	}
 
 	if binPath == "" {
-		output, err := exec.Command("which", "go").CombinedOutput()
+		output, err := call(exec.Command("which", "go"))
 		if err != nil {
-			color.New(color.FgRed).Println(string(output))
+			progress.Stop()
 
-			return err
+			return NewFailure(ExitToolchain, "Error locating go binary", errors.New(strings.TrimSpace(string(output))))
 		}
 
 		binPath = strings.TrimSuffix(filepath.Dir(string(output)), "/")
@@ -277,12 +278,11 @@ func (u util) Upgrade(version string) error {
 
 	cmd = exec.Command("mv", "bima-cli", fmt.Sprintf("%s/bima", binPath))
 	cmd.Dir = wd
-	output, err = cmd.CombinedOutput()
+	output, err = call(cmd)
 	if err != nil {
 		progress.Stop()
-		color.New(color.FgRed).Println(string(output))
 
-		return err
+		return NewFailure(ExitEnvironment, "Error installing bima cli", errors.New(strings.TrimSpace(string(output))))
 	}
 
 	progress.Stop()
@@ -294,47 +294,65 @@ func (u util) Upgrade(version string) error {
 	return nil
 }
 
-func (u util) Build(name string, debug bool) error {
+func build(name string, debug bool, ldflags string, flags []string) error {
 	if debug {
-		return command("go build -race -gcflags \"all=-N -l\" -o %s cmd/main.go").run(name)
+		flags = append([]string{"-race", "-gcflags", "all=-N -l"}, flags...)
 	}
 
-	return command("go build -o %s cmd/main.go").run(name)
-}
+	args := ""
+	for _, flag := range flags {
+		args = fmt.Sprintf("%s%s ", args, escape(flag))
+	}
 
-func (u util) Dump() error {
-	return command("go run dumper/main.go").run()
+	return command("go build %s-ldflags %s -o %s cmd/main.go").run(args, escape(ldflags), escape(name))
 }
 
-func (u util) Kill() error {
-	pid := Pid()
-	if pid == 0 {
-		return nil
-	}
+func escape(value string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(value, "'", `'\''`))
+}
 
-	_ = exec.Command("kill", "-9", strconv.Itoa(pid)).Run()
-	_ = os.Remove(".pid")
+func dump() error {
+	return command("go run dumper/main.go").run()
+}
 
-	return nil
+func Kill(grace time.Duration) error {
+	return Profile("").Stop(grace)
 }
 
-func (u util) Clean() error {
+func clean() error {
 	return command("go mod tidy").run()
 }
 
-func (u util) Update() error {
+func update() error {
 	return command("go get -u").run()
 }
 
-func (u util) Run(file string) error {
+func Run(file string) error {
 	defer func() {
-		_ = u.Kill()
+		_ = Kill(bima.ShutdownTimeout)
 	}()
 
-	return command("go run -race cmd/main.go run %s").run(file)
+	env := configs.Env{}
+	if err := config(&env, file); err != nil {
+		return err
+	}
+
+	logs := &tail{size: 20}
+	stdout, log, err := capture(Profile(""), logs)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go report(env, logs, bima.StartupTimeout, done)
+
+	return command("go run -race cmd/main.go run %s").pipe(stdout, file)
 }
 
-func (u util) Genproto() error {
+func genproto() error {
 	return command(`protoc -Iprotos -Ilibs --go_out=:protos/builds --go-grpc_out=:protos/builds protos/*.proto
 protoc -Iprotos -Ilibs --grpc-gateway_out=logtostderr=true:protos/builds protos/*.proto
 protoc -Iprotos -Ilibs --go_out=:protos/builds --go-grpc_out=:protos/builds libs/bima/*.proto
@@ -343,7 +361,7 @@ protoc -Iprotos -Ilibs --openapiv2_out=swaggers protos/*.proto
 `).run()
 }
 
-func (u util) toolchain() error {
+func toolchain() error {
 	return command(`go install \
 github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-grpc-gateway \
 github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-openapiv2 \
@@ -352,55 +370,6 @@ google.golang.org/grpc/cmd/protoc-gen-go-grpc
 `).run()
 }
 
-func config(config *configs.Env, filePath string, ext string) {
-	switch ext {
-	case ".env":
-		_ = godotenv.Load()
-		parse(config)
-	case ".yaml":
-		content, err := os.ReadFile(filePath)
-		if err != nil {
-			log.Fatalln(err.Error())
-		}
-
-		err = yaml.Unmarshal(content, config)
-		if err != nil {
-			log.Fatalln(err.Error())
-		}
-	case ".json":
-		content, err := os.ReadFile(filePath)
-		if err != nil {
-			log.Fatalln(err.Error())
-		}
-
-		err = json.Unmarshal(content, config)
-		if err != nil {
-			log.Fatalln(err.Error())
-		}
-	}
-}
-
-func parse(config *configs.Env) {
-	config.Debug, _ = strconv.ParseBool(os.Getenv("APP_DEBUG"))
-	config.HttpPort, _ = strconv.Atoi(os.Getenv("APP_PORT"))
-	config.RpcPort, _ = strconv.Atoi(os.Getenv("GRPC_PORT"))
-	dbPort, _ := strconv.Atoi(os.Getenv("DB_PORT"))
-
-	config.ApiPrefix = os.Getenv("API_PREFIX")
-	config.Secret = os.Getenv("APP_SECRET")
-	config.Service = os.Getenv("APP_NAME")
-	config.Db = configs.Db{
-		Host:     os.Getenv("DB_HOST"),
-		Port:     dbPort,
-		User:     os.Getenv("DB_USER"),
-		Password: os.Getenv("DB_PASSWORD"),
-		Name:     os.Getenv("DB_NAME"),
-		Driver:   os.Getenv("DB_DRIVER"),
-	}
-
-	config.CacheLifetime, _ = strconv.Atoi(os.Getenv("CACHE_LIFETIME"))
-}
-
 func NewGenerator(driver string, apiPrefix string) *generators.Factory {
 	return &generators.Factory{
 		Driver:     driver,
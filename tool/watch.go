@@ -0,0 +1,346 @@
+package tool
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bimalabs/cli/bima"
+	"github.com/fatih/color"
+)
+
+const (
+	source change = 1 << iota
+	proto
+	provider
+	setting
+)
+
+type (
+	change int
+
+	snapshot map[string]time.Time
+
+	watcher struct {
+		dir     string
+		sources map[string]bool
+		files   snapshot
+	}
+
+	process struct {
+		cmd  *exec.Cmd
+		done chan error
+	}
+)
+
+func Watch(files []string, overrides []string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	w := &watcher{dir: wd, sources: map[string]bool{}}
+	for _, file := range files {
+		w.sources[w.key(file)] = true
+	}
+
+	w.files = w.scan()
+
+	config, err := Materialize(files, overrides)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = config.Close()
+	}()
+
+	binary := fmt.Sprintf("%s/.bima/watch", wd)
+	if err := os.MkdirAll(filepath.Dir(binary), 0755); err != nil {
+		return err
+	}
+
+	if err := rebuild(binary, proto|provider); err != nil {
+		return err
+	}
+
+	stdout, log, err := capture(Profile(""), &tail{size: 20})
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+
+	app, err := spawn(binary, config.File, stdout)
+	if err != nil {
+		return err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	util := color.New(color.FgGreen, color.Bold)
+	util.Println("Watching for changes...")
+
+	changes := make(chan change)
+	resume := make(chan struct{})
+	go w.watch(changes, resume)
+
+	for {
+		select {
+		case <-signals:
+			if app != nil {
+				app.stop(bima.ShutdownTimeout)
+			}
+
+			_ = os.Remove(".pid")
+
+			return nil
+		case err := <-app.exited():
+			app = nil
+			if err != nil {
+				color.New(color.FgRed).Printf("Application stopped: %s\n", err.Error())
+			}
+
+			color.New(color.FgYellow).Println("Waiting for changes before restarting...")
+		case c := <-changes:
+			if app != nil {
+				app.stop(bima.ShutdownTimeout)
+				app = nil
+			}
+
+			_ = os.Remove(".pid")
+
+			if c&setting != 0 {
+				next, err := Materialize(files, overrides)
+				if err != nil {
+					resume <- struct{}{}
+					color.New(color.FgRed).Println(err.Error())
+					color.New(color.FgRed).Println("Invalid config, waiting for changes...")
+
+					continue
+				}
+
+				_ = config.Close()
+				config = next
+			}
+
+			err := rebuild(binary, c)
+			resume <- struct{}{}
+			if err != nil {
+				color.New(color.FgRed).Println("Build failed, waiting for changes...")
+
+				continue
+			}
+
+			app, err = spawn(binary, config.File, stdout)
+			if err != nil {
+				return err
+			}
+
+			util.Println("Application restarted")
+		}
+	}
+}
+
+func rebuild(binary string, c change) error {
+	progress := Spinner(" Rebuilding application... ")
+	progress.Start()
+	defer progress.Stop()
+
+	tasks := []Task{}
+	if c&proto != 0 {
+		tasks = append(tasks, Genproto())
+	}
+
+	if c&(proto|provider) != 0 {
+		tasks = append(tasks, Dump())
+	}
+
+	if err := NewPipeline(false).Run(append(tasks, Compile(binary, false, "", "-race"))...); err != nil {
+		progress.Stop()
+		color.New(color.FgRed).Println(Reason(err))
+
+		return err
+	}
+
+	return nil
+}
+
+func spawn(binary string, file string, stdout io.Writer) (*process, error) {
+	cmd := exec.Command(binary, "run", file)
+	cmd.Stdout = stdout
+	cmd.Stderr = stdout
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := &process{cmd: cmd, done: make(chan error, 1)}
+	go func() {
+		p.done <- cmd.Wait()
+	}()
+
+	return p, nil
+}
+
+func (p *process) exited() <-chan error {
+	if p == nil {
+		return nil
+	}
+
+	return p.done
+}
+
+func (p *process) stop(grace time.Duration) {
+	if err := p.cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		_ = p.cmd.Process.Kill()
+	}
+
+	select {
+	case <-p.done:
+	case <-time.After(grace):
+		_ = p.cmd.Process.Kill()
+		<-p.done
+	}
+}
+
+func (w *watcher) watch(changes chan<- change, resume <-chan struct{}) {
+	for {
+		time.Sleep(bima.WatchInterval)
+
+		c := w.diff()
+		if c == 0 {
+			continue
+		}
+
+		for {
+			time.Sleep(bima.WatchDebounce)
+
+			next := w.diff()
+			if next == 0 {
+				break
+			}
+
+			c |= next
+		}
+
+		changes <- c
+		<-resume
+
+		w.files = w.scan()
+	}
+}
+
+func (w *watcher) diff() change {
+	current := w.scan()
+
+	var c change
+	for path, modified := range current {
+		if last, ok := w.files[path]; !ok || !last.Equal(modified) {
+			c |= w.classify(path)
+		}
+	}
+
+	for path := range w.files {
+		if _, ok := current[path]; !ok {
+			c |= w.classify(path)
+		}
+	}
+
+	w.files = current
+
+	return c
+}
+
+func (w *watcher) scan() snapshot {
+	files := snapshot{}
+	_ = filepath.WalkDir(w.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(w.dir, path)
+		if entry.IsDir() {
+			switch rel {
+			case ".git", ".bima", ".vscode", "vendor", "node_modules", "swaggers", "protos/builds":
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if w.classify(rel) == 0 {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+
+		files[rel] = info.ModTime()
+
+		return nil
+	})
+
+	for source := range w.sources {
+		if _, ok := files[source]; ok {
+			continue
+		}
+
+		path := source
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(w.dir, path)
+		}
+
+		if info, err := os.Stat(path); err == nil {
+			files[source] = info.ModTime()
+		}
+	}
+
+	return files
+}
+
+func (w *watcher) key(file string) string {
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(w.dir, path)
+	}
+
+	rel, err := filepath.Rel(w.dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return filepath.Clean(path)
+	}
+
+	return rel
+}
+
+func (w *watcher) classify(path string) change {
+	if w.sources[path] {
+		return setting
+	}
+
+	switch filepath.Ext(path) {
+	case ".proto":
+		return proto
+	case ".go":
+		if filepath.Base(path) == "provider.go" || strings.HasPrefix(path, "configs/") {
+			return provider | source
+		}
+
+		return source
+	case ".yaml", ".yml":
+		if path == c {
+			return provider
+		}
+	}
+
+	return 0
+}
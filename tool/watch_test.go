@@ -0,0 +1,98 @@
+package tool
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	dir := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "shared.env")
+
+	w := &watcher{dir: dir, sources: map[string]bool{}}
+	for _, file := range []string{".env", filepath.Join(dir, "configs/local.yaml"), outside} {
+		w.sources[w.key(file)] = true
+	}
+
+	cases := []struct {
+		name   string
+		path   string
+		expect change
+	}{
+		{name: "source", path: "todos/service.go", expect: source},
+		{name: "provider", path: "todos/provider.go", expect: provider | source},
+		{name: "config package", path: "configs/type.go", expect: provider | source},
+		{name: "proto", path: "protos/todo.proto", expect: proto},
+		{name: "modules", path: "configs/modules.yaml", expect: provider},
+		{name: "env", path: ".env", expect: setting},
+		{name: "layered yaml", path: "configs/local.yaml", expect: setting},
+		{name: "outside project", path: outside, expect: setting},
+		{name: "unknown env", path: ".env.prod", expect: 0},
+		{name: "unknown yaml", path: "docs/api.yaml", expect: 0},
+		{name: "text", path: "README.md", expect: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := w.classify(c.path); got != c.expect {
+				t.Fatalf("expect %b got %b", c.expect, got)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "shared.env")
+	for _, file := range []string{filepath.Join(dir, ".env"), filepath.Join(dir, "todos/service.go"), filepath.Join(dir, "protos/todo.proto"), outside} {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := &watcher{dir: dir, sources: map[string]bool{}}
+	for _, file := range []string{".env", outside} {
+		w.sources[w.key(file)] = true
+	}
+
+	w.files = w.scan()
+	if len(w.files) != 4 {
+		t.Fatalf("expect 4 watched files got %v", w.files)
+	}
+
+	if c := w.diff(); c != 0 {
+		t.Fatalf("expect no change got %b", c)
+	}
+
+	touch := func(file string) {
+		modified := time.Now().Add(time.Minute)
+		if err := os.Chtimes(file, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	touch(outside)
+	if c := w.diff(); c != setting {
+		t.Fatalf("expect setting change got %b", c)
+	}
+
+	touch(filepath.Join(dir, ".env"))
+	touch(filepath.Join(dir, "todos/service.go"))
+	if c := w.diff(); c != setting|source {
+		t.Fatalf("expect setting and source change got %b", c)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "protos/todo.proto")); err != nil {
+		t.Fatal(err)
+	}
+
+	if c := w.diff(); c != proto {
+		t.Fatalf("expect proto change got %b", c)
+	}
+}